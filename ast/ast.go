@@ -3,12 +3,15 @@ package ast
 import (
 	"bytes"
 	"monkey/token"
+	"strconv"
 	"strings"
 )
 
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // position of the first char of the node
+	End() token.Position // position immediately after the node
 }
 
 type Statement interface {
@@ -33,6 +36,22 @@ func (p *Program) TokenLiteral() string {
 	return ""
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if n := len(p.Statements); n > 0 {
+		return p.Statements[n-1].End()
+	}
+
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -55,6 +74,16 @@ func (ls *LetStatement) TokenLiteral() string {
 	return ls.Token.Literal
 }
 
+func (ls *LetStatement) Pos() token.Position { return ls.Token.Pos }
+
+func (ls *LetStatement) End() token.Position {
+	if ls.Value != nil {
+		return ls.Value.End()
+	}
+
+	return ls.Name.End()
+}
+
 func (ls *LetStatement) String() string {
 	var out bytes.Buffer
 
@@ -82,6 +111,9 @@ func (i *Identifier) TokenLiteral() string {
 	return i.Token.Literal
 }
 
+func (i *Identifier) Pos() token.Position { return i.Token.Pos }
+func (i *Identifier) End() token.Position { return i.Token.End }
+
 func (i *Identifier) String() string {
 	return i.Value
 }
@@ -97,6 +129,16 @@ func (rs *ReturnStatement) TokenLiteral() string {
 	return rs.Token.Literal
 }
 
+func (rs *ReturnStatement) Pos() token.Position { return rs.Token.Pos }
+
+func (rs *ReturnStatement) End() token.Position {
+	if rs.ReturnValue != nil {
+		return rs.ReturnValue.End()
+	}
+
+	return rs.Token.End
+}
+
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
@@ -111,6 +153,158 @@ func (rs *ReturnStatement) String() string {
 	return out.String()
 }
 
+/*
+* while(<condition>){<body>}
+ */
+type WhileStatement struct {
+	Token     token.Token // the token.WHILE
+	Condition Expression
+	Body      *BlockStatement
+}
+
+func (ws *WhileStatement) statementNode() {}
+
+func (ws *WhileStatement) TokenLiteral() string { return ws.Token.Literal }
+
+func (ws *WhileStatement) Pos() token.Position { return ws.Token.Pos }
+
+func (ws *WhileStatement) End() token.Position { return ws.Body.End() }
+
+func (ws *WhileStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("while")
+	out.WriteString(ws.Condition.String())
+	out.WriteString(" ")
+	out.WriteString(ws.Body.String())
+
+	return out.String()
+}
+
+/*
+* for(<var> in <iterable>){<body>}
+* for(<key>, <value> in <iterable>){<body>}
+ */
+type ForStatement struct {
+	Token    token.Token   // the token.FOR
+	Vars     []*Identifier // one or two loop variables
+	Iterable Expression
+	Body     *BlockStatement
+}
+
+func (fs *ForStatement) statementNode() {}
+
+func (fs *ForStatement) TokenLiteral() string { return fs.Token.Literal }
+
+func (fs *ForStatement) Pos() token.Position { return fs.Token.Pos }
+
+func (fs *ForStatement) End() token.Position { return fs.Body.End() }
+
+func (fs *ForStatement) String() string {
+	var out bytes.Buffer
+	vars := []string{}
+
+	for _, v := range fs.Vars {
+		vars = append(vars, v.String())
+	}
+
+	out.WriteString("for(")
+	out.WriteString(strings.Join(vars, ", "))
+	out.WriteString(" in ")
+	out.WriteString(fs.Iterable.String())
+	out.WriteString(") ")
+	out.WriteString(fs.Body.String())
+
+	return out.String()
+}
+
+/*
+* import "path/to/lib.mk"
+* import "lib" as name
+ */
+type ImportStatement struct {
+	Token token.Token // the token.IMPORT
+	Path  *StringLiteral
+	Alias *Identifier // nil without as
+}
+
+func (is *ImportStatement) statementNode() {}
+
+func (is *ImportStatement) TokenLiteral() string { return is.Token.Literal }
+
+func (is *ImportStatement) Pos() token.Position { return is.Token.Pos }
+
+func (is *ImportStatement) End() token.Position {
+	if is.Alias != nil {
+		return is.Alias.End()
+	}
+
+	return is.Path.End()
+}
+
+func (is *ImportStatement) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(is.TokenLiteral() + " ")
+	out.WriteString(strconv.Quote(is.Path.Value))
+
+	if is.Alias != nil {
+		out.WriteString(" as " + is.Alias.String())
+	}
+
+	out.WriteString(";")
+
+	return out.String()
+}
+
+/*
+* export let name = value;
+ */
+type ExportStatement struct {
+	Token     token.Token // the token.EXPORT
+	Statement *LetStatement
+}
+
+func (es *ExportStatement) statementNode() {}
+
+func (es *ExportStatement) TokenLiteral() string { return es.Token.Literal }
+
+func (es *ExportStatement) Pos() token.Position { return es.Token.Pos }
+
+func (es *ExportStatement) End() token.Position { return es.Statement.End() }
+
+func (es *ExportStatement) String() string {
+	return es.TokenLiteral() + " " + es.Statement.String()
+}
+
+type BreakStatement struct {
+	Token token.Token // the token.BREAK
+}
+
+func (bs *BreakStatement) statementNode() {}
+
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+
+func (bs *BreakStatement) Pos() token.Position { return bs.Token.Pos }
+
+func (bs *BreakStatement) End() token.Position { return bs.Token.End }
+
+func (bs *BreakStatement) String() string { return bs.TokenLiteral() + ";" }
+
+type ContinueStatement struct {
+	Token token.Token // the token.CONTINUE
+}
+
+func (cs *ContinueStatement) statementNode() {}
+
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+
+func (cs *ContinueStatement) Pos() token.Position { return cs.Token.Pos }
+
+func (cs *ContinueStatement) End() token.Position { return cs.Token.End }
+
+func (cs *ContinueStatement) String() string { return cs.TokenLiteral() + ";" }
+
 type ExpressionStatement struct {
 	Token      token.Token // the first token of the expression
 	Expression Expression
@@ -122,6 +316,16 @@ func (es *ExpressionStatement) TokenLiteral() string {
 	return es.Token.Literal
 }
 
+func (es *ExpressionStatement) Pos() token.Position { return es.Token.Pos }
+
+func (es *ExpressionStatement) End() token.Position {
+	if es.Expression != nil {
+		return es.Expression.End()
+	}
+
+	return es.Token.End
+}
+
 func (es *ExpressionStatement) String() string {
 
 	if es.Expression != nil {
@@ -142,10 +346,59 @@ func (s *StringLiteral) TokenLiteral() string {
 	return s.Token.Literal
 }
 
+func (s *StringLiteral) Pos() token.Position { return s.Token.Pos }
+func (s *StringLiteral) End() token.Position { return s.Token.End }
+
 func (s *StringLiteral) String() string {
 	return s.Token.Literal
 }
 
+/*
+* String with embedded expressions
+* e.g "total: ${a + b}!"
+* Parts alternate *StringLiteral and embedded expressions, starting and
+* ending with a (maybe empty) *StringLiteral
+ */
+type InterpolatedString struct {
+	Token token.Token // the first TEMPLATE token
+	Parts []Expression
+}
+
+func (is *InterpolatedString) expressionNode() {}
+
+func (is *InterpolatedString) TokenLiteral() string {
+	return is.Token.Literal
+}
+
+func (is *InterpolatedString) Pos() token.Position { return is.Token.Pos }
+
+func (is *InterpolatedString) End() token.Position {
+	if n := len(is.Parts); n > 0 && is.Parts[n-1] != nil {
+		return is.Parts[n-1].End()
+	}
+
+	return is.Token.End
+}
+
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	for _, part := range is.Parts {
+		if s, ok := part.(*StringLiteral); ok {
+			out.WriteString(s.String())
+			continue
+		}
+
+		out.WriteString("${")
+		if part != nil {
+			out.WriteString(part.String())
+		}
+		out.WriteString("}")
+	}
+
+	return out.String()
+}
+
 /*
 * Integers
 * e.g 1, 789, 10
@@ -161,10 +414,35 @@ func (il *IntegerLiteral) TokenLiteral() string {
 	return il.Token.Literal
 }
 
+func (il *IntegerLiteral) Pos() token.Position { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Position { return il.Token.End }
+
 func (il *IntegerLiteral) String() string {
 	return il.Token.Literal
 }
 
+/*
+* Floats
+* e.g 1.5, 2e10, 0.25
+ */
+type FloatLiteral struct {
+	Token token.Token //FLOAT
+	Value float64
+}
+
+func (fl *FloatLiteral) expressionNode() {}
+
+func (fl *FloatLiteral) TokenLiteral() string {
+	return fl.Token.Literal
+}
+
+func (fl *FloatLiteral) Pos() token.Position { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Position { return fl.Token.End }
+
+func (fl *FloatLiteral) String() string {
+	return fl.Token.Literal
+}
+
 /*
 * Prefix Operator
 * e.g !5, -90, !foo()
@@ -181,6 +459,16 @@ func (pe *PrefixExpression) TokenLiteral() string {
 	return pe.Token.Literal
 }
 
+func (pe *PrefixExpression) Pos() token.Position { return pe.Token.Pos }
+
+func (pe *PrefixExpression) End() token.Position {
+	if pe.Right != nil {
+		return pe.Right.End()
+	}
+
+	return pe.Token.End
+}
+
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 
@@ -209,6 +497,22 @@ func (ie *InfixExpression) TokenLiteral() string {
 	return ie.Token.Literal
 }
 
+func (ie *InfixExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+
+	return ie.Token.Pos
+}
+
+func (ie *InfixExpression) End() token.Position {
+	if ie.Right != nil {
+		return ie.Right.End()
+	}
+
+	return ie.Token.End
+}
+
 func (ie *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -221,6 +525,50 @@ func (ie *InfixExpression) String() string {
 	return out.String()
 }
 
+/*
+* Assignment to an existing binding or to an array element
+* e.g x = 5, total += x, arr[i] = 5
+ */
+type AssignExpression struct {
+	Token  token.Token // The assignment token, e.g: = , +=
+	Target Expression  // the assigned *Identifier or *IndexExpression
+	Value  Expression
+}
+
+func (ae *AssignExpression) expressionNode() {}
+
+func (ae *AssignExpression) TokenLiteral() string {
+	return ae.Token.Literal
+}
+
+func (ae *AssignExpression) Pos() token.Position {
+	if ae.Target != nil {
+		return ae.Target.Pos()
+	}
+
+	return ae.Token.Pos
+}
+
+func (ae *AssignExpression) End() token.Position {
+	if ae.Value != nil {
+		return ae.Value.End()
+	}
+
+	return ae.Token.End
+}
+
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.TokenLiteral() + " ")
+	if ae.Value != nil {
+		out.WriteString(ae.Value.String())
+	}
+
+	return out.String()
+}
+
 /*
 * Boolean literals
 * true, false
@@ -237,6 +585,10 @@ func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
 
 func (b *Boolean) String() string { return b.Token.Literal }
 
+func (b *Boolean) Pos() token.Position { return b.Token.Pos }
+
+func (b *Boolean) End() token.Position { return b.Token.End }
+
 /*
 * If expression
 * if (<condition>) <consequence> else <alternative>
@@ -253,6 +605,16 @@ func (ie *IfExpression) expressionNode() {}
 
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
 
+func (ie *IfExpression) Pos() token.Position { return ie.Token.Pos }
+
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+
+	return ie.Consequence.End()
+}
+
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("if")
@@ -264,20 +626,69 @@ func (ie *IfExpression) String() string {
 		return out.String()
 	}
 
-	out.WriteString("else ")
+	out.WriteString(" else ")
 	out.WriteString(ie.Alternative.String())
 	return out.String()
 }
 
+/*
+* <condition> ? <consequence> : <alternative>
+ */
+type ConditionalExpression struct {
+	Token       token.Token // the ? token
+	Condition   Expression
+	Consequence Expression
+	Alternative Expression
+}
+
+func (ce *ConditionalExpression) expressionNode() {}
+
+func (ce *ConditionalExpression) TokenLiteral() string { return ce.Token.Literal }
+
+func (ce *ConditionalExpression) Pos() token.Position {
+	if ce.Condition != nil {
+		return ce.Condition.Pos()
+	}
+
+	return ce.Token.Pos
+}
+
+func (ce *ConditionalExpression) End() token.Position {
+	if ce.Alternative != nil {
+		return ce.Alternative.End()
+	}
+
+	return ce.Token.End
+}
+
+func (ce *ConditionalExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ce.Condition.String())
+	out.WriteString(" ? ")
+	out.WriteString(ce.Consequence.String())
+	out.WriteString(" : ")
+	out.WriteString(ce.Alternative.String())
+	out.WriteString(")")
+
+	return out.String()
+}
+
 type BlockStatement struct {
 	Token      token.Token //the { token
 	Statements []Statement
+	Rbrace     token.Token //the } token
 }
 
 func (bs *BlockStatement) statementNode() {}
 
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
 
+func (bs *BlockStatement) Pos() token.Position { return bs.Token.Pos }
+
+func (bs *BlockStatement) End() token.Position { return bs.Rbrace.End }
+
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -298,6 +709,10 @@ func (fl *FunctionLiteral) expressionNode() {}
 
 func (fl *FunctionLiteral) TokenLiteral() string { return fl.Token.Literal }
 
+func (fl *FunctionLiteral) Pos() token.Position { return fl.Token.Pos }
+
+func (fl *FunctionLiteral) End() token.Position { return fl.Body.End() }
+
 func (fl *FunctionLiteral) String() string {
 	var out bytes.Buffer
 	params := []string{}
@@ -315,6 +730,40 @@ func (fl *FunctionLiteral) String() string {
 	return out.String()
 }
 
+/*
+* macro(x, y){...}, expanded before evaluation
+ */
+type MacroLiteral struct {
+	Token      token.Token //The 'macro' token
+	Parameters []*Identifier
+	Body       *BlockStatement
+}
+
+func (ml *MacroLiteral) expressionNode() {}
+
+func (ml *MacroLiteral) TokenLiteral() string { return ml.Token.Literal }
+
+func (ml *MacroLiteral) Pos() token.Position { return ml.Token.Pos }
+
+func (ml *MacroLiteral) End() token.Position { return ml.Body.End() }
+
+func (ml *MacroLiteral) String() string {
+	var out bytes.Buffer
+	params := []string{}
+
+	for _, p := range ml.Parameters {
+		params = append(params, p.String())
+	}
+
+	out.WriteString(ml.TokenLiteral())
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ","))
+	out.WriteString(")")
+	out.WriteString(ml.Body.String())
+
+	return out.String()
+}
+
 /*
    e.g.
    add(2,3)
@@ -326,12 +775,23 @@ type CallExpression struct {
 	Token     token.Token //the '(' token
 	Function  Expression  //Identifier or FunctionLiteral
 	Arguments []Expression
+	Rparen    token.Token //the ')' token
 }
 
 func (ce *CallExpression) expressionNode() {}
 
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
 
+func (ce *CallExpression) Pos() token.Position {
+	if ce.Function != nil {
+		return ce.Function.Pos()
+	}
+
+	return ce.Token.Pos
+}
+
+func (ce *CallExpression) End() token.Position { return ce.Rparen.End }
+
 func (ce *CallExpression) String() string {
 
 	var out bytes.Buffer
@@ -352,10 +812,13 @@ func (ce *CallExpression) String() string {
 type ArrayLiteral struct {
 	Token    token.Token // the [ token
 	Elements []Expression
+	Rbracket token.Token // the ] token
 }
 
 func (a *ArrayLiteral) expressionNode()      {}
 func (a *ArrayLiteral) TokenLiteral() string { return a.Token.Literal }
+func (a *ArrayLiteral) Pos() token.Position  { return a.Token.Pos }
+func (a *ArrayLiteral) End() token.Position  { return a.Rbracket.End }
 
 func (a *ArrayLiteral) String() string {
 
@@ -373,14 +836,179 @@ func (a *ArrayLiteral) String() string {
 	return out.String()
 }
 
+/*
+* Hash literal
+* e.g {"name": "monkey", 1: true}
+ */
+type HashLiteral struct {
+	Token  token.Token // the { token
+	Pairs  []HashPair  // in source order
+	Rbrace token.Token // the } token
+}
+
+type HashPair struct {
+	Key   Expression
+	Value Expression
+}
+
+func (hl *HashLiteral) expressionNode()      {}
+func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Position  { return hl.Rbrace.End }
+
+func (hl *HashLiteral) String() string {
+
+	var out bytes.Buffer
+	pairs := []string{}
+
+	for _, pair := range hl.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+/*
+* match(<value>) { <pattern> => <expression>, <pattern> if <guard> => <expression> }
+ */
+type MatchExpression struct {
+	Token  token.Token // the match token
+	Value  Expression
+	Arms   []*MatchArm
+	Rbrace token.Token // the } token
+}
+
+/*
+* A pattern is one of:
+* an Identifier, binding the value (_ matches anything and binds nothing)
+* a literal: integer, float, string or boolean, possibly negated
+* an ArrayPattern or a HashPattern, matching element by element
+ */
+type MatchArm struct {
+	Pattern Expression
+	Guard   Expression // nil without an if
+	Body    Expression
+}
+
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" if " + ma.Guard.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+
+	return out.String()
+}
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Pos() token.Position  { return me.Token.Pos }
+func (me *MatchExpression) End() token.Position  { return me.Rbrace.End }
+
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+	arms := []string{}
+
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString("match(")
+	out.WriteString(me.Value.String())
+	out.WriteString(") {")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+/*
+* [<pattern>, ..., ...<rest>]
+ */
+type ArrayPattern struct {
+	Token    token.Token // the [ token
+	Elements []Expression
+	Rest     *Identifier // nil without a ...rest
+	Rbracket token.Token // the ] token
+}
+
+func (ap *ArrayPattern) expressionNode()      {}
+func (ap *ArrayPattern) TokenLiteral() string { return ap.Token.Literal }
+func (ap *ArrayPattern) Pos() token.Position  { return ap.Token.Pos }
+func (ap *ArrayPattern) End() token.Position  { return ap.Rbracket.End }
+
+func (ap *ArrayPattern) String() string {
+	var out bytes.Buffer
+	elements := []string{}
+
+	for _, el := range ap.Elements {
+		elements = append(elements, el.String())
+	}
+	if ap.Rest != nil {
+		elements = append(elements, "..."+ap.Rest.String())
+	}
+
+	out.WriteString("[")
+	out.WriteString(strings.Join(elements, ", "))
+	out.WriteString("]")
+
+	return out.String()
+}
+
+/*
+* {<key>: <pattern>, ...}, keys are literals
+ */
+type HashPattern struct {
+	Token  token.Token // the { token
+	Pairs  []HashPair  // Value holds the pattern
+	Rbrace token.Token // the } token
+}
+
+func (hp *HashPattern) expressionNode()      {}
+func (hp *HashPattern) TokenLiteral() string { return hp.Token.Literal }
+func (hp *HashPattern) Pos() token.Position  { return hp.Token.Pos }
+func (hp *HashPattern) End() token.Position  { return hp.Rbrace.End }
+
+func (hp *HashPattern) String() string {
+	var out bytes.Buffer
+	pairs := []string{}
+
+	for _, pair := range hp.Pairs {
+		pairs = append(pairs, pair.Key.String()+": "+pair.Value.String())
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
 type IndexExpression struct {
-	Token token.Token // The [ token
-	Left  Expression
-	Index Expression
+	Token    token.Token // The [ token
+	Left     Expression
+	Index    Expression
+	Rbracket token.Token // The ] token
 }
 
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) End() token.Position  { return ie.Rbracket.End }
+
+func (ie *IndexExpression) Pos() token.Position {
+	if ie.Left != nil {
+		return ie.Left.Pos()
+	}
+
+	return ie.Token.Pos
+}
 
 func (ie *IndexExpression) String() string {
 
@@ -393,3 +1021,83 @@ func (ie *IndexExpression) String() string {
 
 	return out.String()
 }
+
+/*
+* a[start:stop] or a[start:stop:step], every part is optional: a[:], a[::-1]
+ */
+type SliceExpression struct {
+	Token    token.Token // The [ token
+	Left     Expression
+	Start    Expression  // nil if omitted
+	Stop     Expression  // nil if omitted
+	Step     Expression  // nil if omitted
+	Rbracket token.Token // The ] token
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+
+func (se *SliceExpression) Pos() token.Position {
+	if se.Left != nil {
+		return se.Left.Pos()
+	}
+
+	return se.Token.Pos
+}
+
+func (se *SliceExpression) End() token.Position { return se.Rbracket.End }
+
+func (se *SliceExpression) String() string {
+	part := func(exp Expression) string {
+		if exp == nil {
+			return ""
+		}
+		return exp.String()
+	}
+
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	out.WriteString(part(se.Start) + ":" + part(se.Stop))
+	if se.Step != nil {
+		out.WriteString(":" + se.Step.String())
+	}
+	out.WriteString("])")
+
+	return out.String()
+}
+
+/*
+* obj.name, a field of a hash or a module export.
+* Called, obj.name(args) is a method call
+ */
+type MemberExpression struct {
+	Token    token.Token // The . token
+	Object   Expression
+	Property *Identifier
+}
+
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) End() token.Position  { return me.Property.End() }
+
+func (me *MemberExpression) Pos() token.Position {
+	if me.Object != nil {
+		return me.Object.Pos()
+	}
+
+	return me.Token.Pos
+}
+
+func (me *MemberExpression) String() string {
+
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	out.WriteString(".")
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
+
+	return out.String()
+}
@@ -0,0 +1,179 @@
+package ast
+
+type ModifierFunc func(Node) Node
+
+/*
+* Modify walks the tree depth first, replacing every node with what the
+* modifier returns for it. Children are modified before their parent.
+* Identifiers binding names (let, parameters, loop variables), member
+* names and match patterns are left as they are.
+* The tree passed in is not changed, nodes with children are copied: the
+* same quoted body can be modified again on every evaluation
+ */
+func Modify(node Node, modifier ModifierFunc) Node {
+
+	switch n := node.(type) {
+
+	case *Program:
+		c := *n
+		c.Statements = modifyStatements(n.Statements, modifier)
+		node = &c
+
+	case *ExpressionStatement:
+		c := *n
+		c.Expression, _ = Modify(n.Expression, modifier).(Expression)
+		node = &c
+
+	case *BlockStatement:
+		c := *n
+		c.Statements = modifyStatements(n.Statements, modifier)
+		node = &c
+
+	case *ReturnStatement:
+		c := *n
+		c.ReturnValue, _ = Modify(n.ReturnValue, modifier).(Expression)
+		node = &c
+
+	case *LetStatement:
+		c := *n
+		c.Value, _ = Modify(n.Value, modifier).(Expression)
+		node = &c
+
+	case *ExportStatement:
+		c := *n
+		c.Statement, _ = Modify(n.Statement, modifier).(*LetStatement)
+		node = &c
+
+	case *WhileStatement:
+		c := *n
+		c.Condition, _ = Modify(n.Condition, modifier).(Expression)
+		c.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+		node = &c
+
+	case *ForStatement:
+		c := *n
+		c.Iterable, _ = Modify(n.Iterable, modifier).(Expression)
+		c.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+		node = &c
+
+	case *PrefixExpression:
+		c := *n
+		c.Right, _ = Modify(n.Right, modifier).(Expression)
+		node = &c
+
+	case *InfixExpression:
+		c := *n
+		c.Left, _ = Modify(n.Left, modifier).(Expression)
+		c.Right, _ = Modify(n.Right, modifier).(Expression)
+		node = &c
+
+	case *AssignExpression:
+		c := *n
+		c.Target, _ = Modify(n.Target, modifier).(Expression)
+		c.Value, _ = Modify(n.Value, modifier).(Expression)
+		node = &c
+
+	case *IndexExpression:
+		c := *n
+		c.Left, _ = Modify(n.Left, modifier).(Expression)
+		c.Index, _ = Modify(n.Index, modifier).(Expression)
+		node = &c
+
+	case *MemberExpression:
+		c := *n
+		c.Object, _ = Modify(n.Object, modifier).(Expression)
+		node = &c
+
+	case *SliceExpression:
+		c := *n
+		c.Left, _ = Modify(n.Left, modifier).(Expression)
+		if n.Start != nil {
+			c.Start, _ = Modify(n.Start, modifier).(Expression)
+		}
+		if n.Stop != nil {
+			c.Stop, _ = Modify(n.Stop, modifier).(Expression)
+		}
+		if n.Step != nil {
+			c.Step, _ = Modify(n.Step, modifier).(Expression)
+		}
+		node = &c
+
+	case *IfExpression:
+		c := *n
+		c.Condition, _ = Modify(n.Condition, modifier).(Expression)
+		c.Consequence, _ = Modify(n.Consequence, modifier).(*BlockStatement)
+		if n.Alternative != nil {
+			c.Alternative, _ = Modify(n.Alternative, modifier).(*BlockStatement)
+		}
+		node = &c
+
+	case *ConditionalExpression:
+		c := *n
+		c.Condition, _ = Modify(n.Condition, modifier).(Expression)
+		c.Consequence, _ = Modify(n.Consequence, modifier).(Expression)
+		c.Alternative, _ = Modify(n.Alternative, modifier).(Expression)
+		node = &c
+
+	case *MatchExpression:
+		c := *n
+		c.Value, _ = Modify(n.Value, modifier).(Expression)
+		c.Arms = make([]*MatchArm, len(n.Arms))
+		for i, arm := range n.Arms {
+			a := *arm
+			if arm.Guard != nil {
+				a.Guard, _ = Modify(arm.Guard, modifier).(Expression)
+			}
+			a.Body, _ = Modify(arm.Body, modifier).(Expression)
+			c.Arms[i] = &a
+		}
+		node = &c
+
+	case *FunctionLiteral:
+		c := *n
+		c.Body, _ = Modify(n.Body, modifier).(*BlockStatement)
+		node = &c
+
+	case *CallExpression:
+		c := *n
+		c.Function, _ = Modify(n.Function, modifier).(Expression)
+		c.Arguments = modifyExpressions(n.Arguments, modifier)
+		node = &c
+
+	case *ArrayLiteral:
+		c := *n
+		c.Elements = modifyExpressions(n.Elements, modifier)
+		node = &c
+
+	case *HashLiteral:
+		c := *n
+		c.Pairs = make([]HashPair, len(n.Pairs))
+		for i, pair := range n.Pairs {
+			c.Pairs[i].Key, _ = Modify(pair.Key, modifier).(Expression)
+			c.Pairs[i].Value, _ = Modify(pair.Value, modifier).(Expression)
+		}
+		node = &c
+
+	case *InterpolatedString:
+		c := *n
+		c.Parts = modifyExpressions(n.Parts, modifier)
+		node = &c
+	}
+
+	return modifier(node)
+}
+
+func modifyStatements(statements []Statement, modifier ModifierFunc) []Statement {
+	modified := make([]Statement, len(statements))
+	for i, statement := range statements {
+		modified[i], _ = Modify(statement, modifier).(Statement)
+	}
+	return modified
+}
+
+func modifyExpressions(expressions []Expression, modifier ModifierFunc) []Expression {
+	modified := make([]Expression, len(expressions))
+	for i, expression := range expressions {
+		modified[i], _ = Modify(expression, modifier).(Expression)
+	}
+	return modified
+}
@@ -1,6 +1,14 @@
 package evaluator
 
-import "monkey/object"
+import (
+	"math"
+	lex "monkey/lexer"
+	"monkey/object"
+	"monkey/token"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
 
 var builtins = map[string]*object.Builtin{
 	"len": &object.Builtin{
@@ -14,7 +22,107 @@ var builtins = map[string]*object.Builtin{
 			}
 
 			strObj, _ := args[0].(*object.String)
-			return &object.Integer{Value: int64(len(strObj.Value))}
+			return &object.Integer{Value: int64(utf8.RuneCountInString(strObj.Value))}
 		},
 	},
+	//range(end), range(start, end), range(start, end, step)
+	"range": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) < 1 || len(args) > 3 {
+				return newError("wrong number of arguments. got=%d, want=1..3", len(args))
+			}
+
+			values := []int64{}
+			for _, arg := range args {
+				integer, ok := arg.(*object.Integer)
+				if !ok {
+					return newError("argument to `range` not supported, got %s", arg.Type())
+				}
+				values = append(values, integer.Value)
+			}
+
+			r := &object.Range{End: values[0], Step: 1}
+			if len(values) > 1 {
+				r.Start, r.End = values[0], values[1]
+			}
+			if len(values) > 2 {
+				r.Step = values[2]
+			}
+
+			if r.Step == 0 {
+				return newError("range step cannot be zero")
+			}
+
+			return r
+		},
+	},
+	//int(x): truncates floats, parses strings written like integer literals
+	"int": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return arg
+			case *object.Float:
+				if math.IsNaN(arg.Value) || math.IsInf(arg.Value, 0) ||
+					arg.Value >= math.MaxInt64 || arg.Value < math.MinInt64 {
+					return newError("cannot convert %s to INTEGER", arg.Inspect())
+				}
+				return &object.Integer{Value: int64(arg.Value)}
+			case *object.String:
+				value, ok := parseInteger(arg.Value)
+				if !ok {
+					return newError("cannot convert %q to INTEGER", arg.Value)
+				}
+				return &object.Integer{Value: value}
+			}
+
+			return newError("argument to `int` not supported, got %s", args[0].Type())
+		},
+	},
+	//float(x): converts integers, parses strings
+	"float": &object.Builtin{
+		Fn: func(args ...object.Object) object.Object {
+			if len(args) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(args))
+			}
+
+			switch arg := args[0].(type) {
+			case *object.Integer:
+				return &object.Float{Value: float64(arg.Value)}
+			case *object.Float:
+				return arg
+			case *object.String:
+				value, err := strconv.ParseFloat(arg.Value, 64)
+				if err != nil {
+					return newError("cannot convert %q to FLOAT", arg.Value)
+				}
+				return &object.Float{Value: value}
+			}
+
+			return newError("argument to `float` not supported, got %s", args[0].Type())
+		},
+	},
+}
+
+/*
+* An integer literal with an optional sign: 42, -1_000, 0x10.
+* The literal follows the lexer's rules, 0755 is not octal but malformed
+ */
+func parseInteger(s string) (int64, bool) {
+	literal := s
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		literal = s[1:]
+	}
+
+	tok := lex.New(literal).NextToken()
+	if tok.Type != token.INT || tok.Literal != literal {
+		return 0, false
+	}
+
+	value, err := strconv.ParseInt(s, 0, 64)
+	return value, err == nil
 }
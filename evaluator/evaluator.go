@@ -2,9 +2,11 @@ package evaluator
 
 import (
 	"fmt"
+	"math"
 	"monkey/ast"
 	"monkey/object"
 	"monkey/token"
+	"strings"
 )
 
 var (
@@ -14,6 +16,17 @@ var (
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	obj := eval(node, env)
+
+	//stamp the error with the innermost node that produced it
+	if err, ok := obj.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+
+	return obj
+}
+
+func eval(node ast.Node, env *object.Environment) object.Object {
 
 	switch v := node.(type) {
 
@@ -29,9 +42,27 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.ReturnStatement:
 		return evalReturnStatement(v, env)
 
+	case *ast.WhileStatement:
+		return evalWhileStatement(v, env)
+
+	case *ast.ForStatement:
+		return evalForStatement(v, env)
+
+	case *ast.ImportStatement:
+		return evalImportStatement(v, env)
+
+	case *ast.ExportStatement:
+		return newError("export is only allowed at the top level of a module")
+
+	case *ast.BreakStatement:
+		return &object.Break{Pos: v.Pos()}
+
+	case *ast.ContinueStatement:
+		return &object.Continue{Pos: v.Pos()}
+
 	case *ast.LetStatement:
 		val := Eval(v.Value, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
 		//bind the identifier
@@ -40,13 +71,22 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.FunctionLiteral:
 		return evalFunction(v, env)
 
+	case *ast.MacroLiteral:
+		return newError("macro must be defined by a top-level let: %s", v)
+
 	//Expressions
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: v.Value}
 
+	case *ast.FloatLiteral:
+		return &object.Float{Value: v.Value}
+
 	case *ast.StringLiteral:
 		return &object.String{Value: v.Value}
 
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(v, env)
+
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(v.Value)
 
@@ -56,20 +96,40 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.InfixExpression:
 		return evalInfixExpression(v, env)
 
+	case *ast.AssignExpression:
+		return evalAssignExpression(v, env)
+
 	case *ast.IfExpression:
 		return evalIfExpression(v, env)
 
+	case *ast.ConditionalExpression:
+		return evalConditionalExpression(v, env)
+
+	case *ast.MatchExpression:
+		return evalMatchExpression(v, env)
+
 	case *ast.Identifier:
 		return evalIdentifier(v, env)
 
 	case *ast.CallExpression:
+		if isQuoteCall(v) {
+			if len(v.Arguments) != 1 {
+				return newError("wrong number of arguments. got=%d, want=1", len(v.Arguments))
+			}
+			return quote(v.Arguments[0], env)
+		}
+
+		if member, ok := v.Function.(*ast.MemberExpression); ok {
+			return evalMethodCall(member, v.Arguments, env)
+		}
+
 		function := Eval(v.Function, env)
-		if isError(function) {
+		if isAbrupt(function) {
 			return function
 		}
 
 		args := evalExpressions(v.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
+		if len(args) == 1 && isAbrupt(args[0]) {
 			return args[0]
 		}
 
@@ -78,59 +138,248 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(v.Elements, env)
-		if len(elements) == 1 && isError(elements[0]) {
+		if len(elements) == 1 && isAbrupt(elements[0]) {
 			return elements[0]
 		}
 
 		return &object.Array{Elements: elements}
 
+	case *ast.HashLiteral:
+		return evalHashLiteral(v, env)
+
 	case *ast.IndexExpression:
 		left := Eval(v.Left, env)
-		if isError(left) {
+		if isAbrupt(left) {
 			return left
 		}
 
 		index := Eval(v.Index, env)
-		if isError(index) {
+		if isAbrupt(index) {
 			return index
 		}
 
-		return evalIndexExpression(left, index)
+		return evalIndexExpression(left, index, env.Options().StrictIndexing)
+
+	case *ast.MemberExpression:
+		return evalMemberExpression(v, env)
+
+	case *ast.SliceExpression:
+		return evalSliceExpression(v, env)
 	}
 
 	return nil
 }
 
-func evalIndexExpression(left, index object.Object) object.Object {
+func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
+	hash := object.NewHash()
 
-	arrayOb, ok := left.(*object.Array)
-	if !ok {
-		return newError("index operator not supported: %s", left.Type())
+	for _, pair := range node.Pairs {
+		key := Eval(pair.Key, env)
+		if isAbrupt(key) {
+			return key
+		}
+
+		hashKey, ok := key.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", key.Type())
+		}
+
+		value := Eval(pair.Value, env)
+		if isAbrupt(value) {
+			return value
+		}
+
+		hash.Set(hashKey, value)
+	}
+
+	return hash
+}
+
+// strict: an index out of range is an error instead of NULL
+func evalIndexExpression(left, index object.Object, strict bool) object.Object {
+
+	if hash, ok := left.(*object.Hash); ok {
+		return evalHashIndexExpression(hash, index)
+	}
+
+	if module, ok := left.(*object.Module); ok {
+		return evalModuleIndexExpression(module, index)
 	}
 
 	indxOb, ok := index.(*object.Integer)
-	if !ok {
-		return newError("Invalid index")
+
+	switch left := left.(type) {
+	case *object.Array:
+		if !ok {
+			return newError("Invalid index")
+		}
+
+		idx, inRange := resolveIndex(indxOb.Value, len(left.Elements))
+		if !inRange {
+			return indexOutOfRange(indxOb.Value, len(left.Elements), strict)
+		}
+
+		return left.Elements[idx]
+
+	case *object.String:
+		if !ok {
+			return newError("Invalid index")
+		}
+
+		runes := []rune(left.Value)
+		idx, inRange := resolveIndex(indxOb.Value, len(runes))
+		if !inRange {
+			return indexOutOfRange(indxOb.Value, len(runes), strict)
+		}
+
+		return &object.String{Value: string(runes[idx])}
+	}
+
+	return newError("index operator not supported: %s", left.Type())
+}
+
+// negative indexes count from the end, a[-1] is the last element
+func resolveIndex(idx int64, length int) (int64, bool) {
+	if idx < 0 {
+		idx += int64(length)
+	}
+	return idx, idx >= 0 && idx < int64(length)
+}
+
+func indexOutOfRange(idx int64, length int, strict bool) object.Object {
+	if strict {
+		return newError("index out of range: %d (length %d)", idx, length)
+	}
+	return NULL
+}
+
+/*
+* Slices copy: the result is a new array or string.
+* Negative start and stop count from the end, out of range ones are
+* clamped. A negative step walks backwards, a[::-1] reverses.
+* Strings are sliced by rune
+ */
+func evalSliceExpression(se *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(se.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+
+	bounds := []*int64{nil, nil, nil}
+	for i, exp := range []ast.Expression{se.Start, se.Stop, se.Step} {
+		if exp == nil {
+			continue
+		}
+
+		obj := Eval(exp, env)
+		if isAbrupt(obj) {
+			return obj
+		}
+
+		integer, ok := obj.(*object.Integer)
+		if !ok {
+			return newError("slice index must be INTEGER, got %s", obj.Type())
+		}
+		bounds[i] = &integer.Value
 	}
 
-	idx := indxOb.Value
-	max := int64(len(arrayOb.Elements) - 1)
+	step := int64(1)
+	if bounds[2] != nil {
+		step = *bounds[2]
+	}
+	if step == 0 {
+		return newError("slice step cannot be zero")
+	}
+
+	switch left := left.(type) {
+
+	case *object.Array:
+		elements := []object.Object{}
+		for _, i := range sliceIndices(int64(len(left.Elements)), bounds[0], bounds[1], step) {
+			elements = append(elements, left.Elements[i])
+		}
+		return &object.Array{Elements: elements}
+
+	case *object.String:
+		runes := []rune(left.Value)
+		sliced := []rune{}
+		for _, i := range sliceIndices(int64(len(runes)), bounds[0], bounds[1], step) {
+			sliced = append(sliced, runes[i])
+		}
+		return &object.String{Value: string(sliced)}
+	}
+
+	return newError("slice operator not supported: %s", left.Type())
+}
+
+// The indexes selected by [start:stop:step] in a sequence of the given length
+func sliceIndices(length int64, start, stop *int64, step int64) []int64 {
 
-	if idx < 0 || idx > max {
+	//resolves a bound, lower and upper are the limits it is clamped to
+	clamp := func(bound *int64, def, lower, upper int64) int64 {
+		if bound == nil {
+			return def
+		}
+
+		i := *bound
+		if i < 0 {
+			i += length
+		}
+		if i < lower {
+			return lower
+		}
+		if i > upper {
+			return upper
+		}
+		return i
+	}
+
+	var from, to int64
+	if step > 0 {
+		from = clamp(start, 0, 0, length)
+		to = clamp(stop, length, 0, length)
+	} else {
+		//walking backwards, -1 is "before the first element"
+		from = clamp(start, length-1, -1, length-1)
+		to = clamp(stop, -1, -1, length-1)
+	}
+
+	indices := []int64{}
+	for i := from; (step > 0 && i < to) || (step < 0 && i > to); i += step {
+		indices = append(indices, i)
+	}
+
+	return indices
+}
+
+// A missing key is NULL
+func evalHashIndexExpression(hash *object.Hash, index object.Object) object.Object {
+
+	key, ok := index.(object.Hashable)
+	if !ok {
+		return newError("unusable as hash key: %s", index.Type())
+	}
+
+	pair, ok := hash.Pairs[key.HashKey()]
+	if !ok {
 		return NULL
 	}
 
-	return arrayOb.Elements[idx]
+	return pair.Value
 }
 
 func applyFunction(fn object.Object, args []object.Object) object.Object {
 
 	switch fn := fn.(type) {
 	case *object.Function:
+		if len(args) != len(fn.Parameters) {
+			return newError("wrong number of arguments. got=%d, want=%d", len(args), len(fn.Parameters))
+		}
+
 		extendedEnv := extendFunctionEnv(fn, args)
 		evaluated := Eval(fn.Body, extendedEnv)
 
-		return unwrapReturnValue(evaluated)
+		return unwrapReturnValue(outsideLoopError(evaluated))
 
 	case *object.Builtin:
 		return fn.Fn(args...)
@@ -146,6 +395,18 @@ func unwrapReturnValue(obj object.Object) object.Object {
 	return obj
 }
 
+// A break or continue that reached a function body or the program
+// was not inside a loop
+func outsideLoopError(obj object.Object) object.Object {
+	switch signal := obj.(type) {
+	case *object.Break:
+		return &object.Error{Message: "break outside loop", Pos: signal.Pos}
+	case *object.Continue:
+		return &object.Error{Message: "continue outside loop", Pos: signal.Pos}
+	}
+	return obj
+}
+
 func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
 	env := object.ExtendEnvironment(fn.Env)
 	for i, param := range fn.Parameters {
@@ -158,7 +419,7 @@ func evalExpressions(args []ast.Expression, env *object.Environment) []object.Ob
 	var evaluated []object.Object
 	for _, arg := range args {
 		obj := Eval(arg, env)
-		if isError(obj) {
+		if isAbrupt(obj) {
 			return []object.Object{obj}
 		}
 		evaluated = append(evaluated, obj)
@@ -171,6 +432,25 @@ func evalFunction(node *ast.FunctionLiteral, env *object.Environment) object.Obj
 	return &object.Function{Parameters: node.Parameters, Body: node.Body, Env: env}
 }
 
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out strings.Builder
+
+	for _, part := range node.Parts {
+		obj := Eval(part, env)
+		if isAbrupt(obj) {
+			return obj
+		}
+
+		if obj == nil {
+			return newError("invalid expression in string: %s", part.String())
+		}
+
+		out.WriteString(obj.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
+
 func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object {
 	if val, ok := env.Get(node.Value); ok {
 		return val
@@ -185,7 +465,7 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 
 func evalReturnStatement(node *ast.ReturnStatement, env *object.Environment) object.Object {
 	v := Eval(node.ReturnValue, env)
-	if isError(v) {
+	if isAbrupt(v) {
 		return v
 	}
 
@@ -195,7 +475,7 @@ func evalReturnStatement(node *ast.ReturnStatement, env *object.Environment) obj
 func evalIfExpression(exp *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(exp.Condition, env)
 
-	if isError(condition) {
+	if isAbrupt(condition) {
 		return condition
 	}
 
@@ -208,6 +488,133 @@ func evalIfExpression(exp *ast.IfExpression, env *object.Environment) object.Obj
 	return NULL
 }
 
+// Only the chosen branch is evaluated
+func evalConditionalExpression(exp *ast.ConditionalExpression, env *object.Environment) object.Object {
+	condition := Eval(exp.Condition, env)
+
+	if isAbrupt(condition) {
+		return condition
+	}
+
+	if isTruthy(condition) {
+		return Eval(exp.Consequence, env)
+	}
+
+	return Eval(exp.Alternative, env)
+}
+
+/*
+* The arms are tried in order, the first one whose pattern matches and
+* whose guard, if any, is truthy is evaluated. Every arm gets its own
+* environment for the pattern's bindings, visible in the guard and the body
+ */
+func evalMatchExpression(me *ast.MatchExpression, env *object.Environment) object.Object {
+	value := Eval(me.Value, env)
+	if isAbrupt(value) {
+		return value
+	}
+
+	for _, arm := range me.Arms {
+		armEnv := object.ExtendEnvironment(env)
+
+		matched, err := matchPattern(arm.Pattern, value, armEnv)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isAbrupt(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		return Eval(arm.Body, armEnv)
+	}
+
+	return newError("no match arm for value: %s", value.Inspect())
+}
+
+// Binds the pattern's identifiers in env, err is set if the pattern can't be evaluated
+func matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) (matched bool, err object.Object) {
+
+	switch pattern := pattern.(type) {
+
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, value)
+		}
+		return true, nil
+
+	case *ast.ArrayPattern:
+		array, ok := value.(*object.Array)
+		if !ok {
+			return false, nil
+		}
+
+		n := len(pattern.Elements)
+		if len(array.Elements) < n || pattern.Rest == nil && len(array.Elements) != n {
+			return false, nil
+		}
+
+		for i, element := range pattern.Elements {
+			if matched, err := matchPattern(element, array.Elements[i], env); !matched || err != nil {
+				return false, err
+			}
+		}
+
+		if pattern.Rest != nil && pattern.Rest.Value != "_" {
+			rest := make([]object.Object, len(array.Elements)-n)
+			copy(rest, array.Elements[n:])
+			env.Set(pattern.Rest.Value, &object.Array{Elements: rest})
+		}
+		return true, nil
+
+	case *ast.HashPattern:
+		hash, ok := value.(*object.Hash)
+		if !ok {
+			return false, nil
+		}
+
+		//keys not in the pattern are ignored
+		for _, pair := range pattern.Pairs {
+			key := Eval(pair.Key, env)
+			if isAbrupt(key) {
+				return false, key
+			}
+
+			hashKey, ok := key.(object.Hashable)
+			if !ok {
+				return false, newError("unusable as hash key: %s", key.Type())
+			}
+
+			entry, ok := hash.Pairs[hashKey.HashKey()]
+			if !ok {
+				return false, nil
+			}
+
+			if matched, err := matchPattern(pair.Value, entry.Value, env); !matched || err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+
+	//a literal
+	expected := Eval(pattern, env)
+	if isAbrupt(expected) {
+		return false, expected
+	}
+
+	return objectsEqual(expected, value), nil
+}
+
 func isTruthy(o object.Object) bool {
 
 	//null and false are false, all other are true
@@ -221,29 +628,107 @@ func isTruthy(o object.Object) bool {
 
 func evalInfixExpression(exp *ast.InfixExpression, env *object.Environment) object.Object {
 
-	right := Eval(exp.Right, env)
-	if isError(right) {
-		return right
+	if exp.Token.Type == token.AND || exp.Token.Type == token.OR {
+		return evalLogicalExpression(exp, env)
 	}
 
 	left := Eval(exp.Left, env)
-	if isError(left) {
+	if isAbrupt(left) {
 		return left
 	}
 
+	right := Eval(exp.Right, env)
+	if isAbrupt(right) {
+		return right
+	}
+
+	return evalInfixOperator(exp.Token.Type, left, right)
+}
+
+func evalInfixOperator(op token.TokenType, left, right object.Object) object.Object {
+
 	switch {
 	case (right.Type() == object.INTEGER_OBJ && left.Type() == object.INTEGER_OBJ):
-		return evalIntegerInfixExpression(exp.Token.Type, left, right)
+		return evalIntegerInfixExpression(op, left, right)
+	case (isNumber(right) && isNumber(left)):
+		return evalFloatInfixExpression(op, left, right)
 	case (right.Type() == object.BOOLEAN_OBJ && left.Type() == object.BOOLEAN_OBJ):
-		return evalBooleanInfixExpression(exp.Token.Type, left, right)
+		return evalBooleanInfixExpression(op, left, right)
 	case (right.Type() == object.STRING_OBJ && left.Type() == object.STRING_OBJ):
-		return evalStringInfixExpression(exp.Token.Type, left, right)
+		return evalStringInfixExpression(op, left, right)
+	case (right.Type() == object.ARRAY_OBJ && left.Type() == object.ARRAY_OBJ):
+		return evalArrayInfixExpression(op, left, right)
 	case (right.Type() != left.Type()):
-		return newError("type mismatch: %s %s %s", left.Type(), exp.Token.Type, right.Type())
+		return newError("type mismatch: %s %s %s", left.Type(), op, right.Type())
 
 	default:
-		return newError("unknown operator: %s %s %s", left.Type(), exp.Token.Type, right.Type())
+		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+}
+
+// The operator applied by a compound assignment, e.g += applies +
+var compoundOperators = map[token.TokenType]token.TokenType{
+	token.PLUS_ASSIGN:     token.PLUS,
+	token.MINUS_ASSIGN:    token.MINUS,
+	token.ASTERISK_ASSIGN: token.ASTERISK,
+	token.SLASH_ASSIGN:    token.SLASH,
+}
+
+/*
+* x = v updates the nearest existing binding of x, x += v is x = x + v
+* The assigned value is the value of the expression
+ */
+func evalAssignExpression(exp *ast.AssignExpression, env *object.Environment) object.Object {
+	switch target := exp.Target.(type) {
+	case *ast.IndexExpression:
+		return evalIndexAssignExpression(exp, target, env)
+	case *ast.MemberExpression:
+		return evalMemberAssignExpression(exp, target, env)
+	}
+
+	name := exp.Target.(*ast.Identifier).Value
+
+	val := Eval(exp.Value, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	if op, ok := compoundOperators[exp.Token.Type]; ok {
+		current, ok := env.Get(name)
+		if !ok {
+			return newError("assignment to undeclared variable: %s", name)
+		}
+
+		val = evalInfixOperator(op, current, val)
+		if isAbrupt(val) {
+			return val
+		}
+	}
+
+	if !env.Assign(name, val) {
+		return newError("assignment to undeclared variable: %s", name)
+	}
+
+	return val
+}
+
+/*
+* Short-circuit && and ||
+* The right operand is evaluated only if the left one does not decide the
+* result, the deciding operand is returned as is: false || "a" is "a"
+ */
+func evalLogicalExpression(exp *ast.InfixExpression, env *object.Environment) object.Object {
+
+	left := Eval(exp.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+
+	if isTruthy(left) == (exp.Token.Type == token.OR) {
+		return left
 	}
+
+	return Eval(exp.Right, env)
 }
 
 func newError(format string, a ...interface{}) *object.Error {
@@ -268,14 +753,154 @@ func evalStringInfixExpression(op token.TokenType, left, right object.Object) ob
 	rvalue := right.(*object.String).Value
 	lvalue := left.(*object.String).Value
 
+	//lexicographic order, by bytes
 	switch op {
 	case token.PLUS:
 		return &object.String{Value: lvalue + rvalue}
+	case token.LT:
+		return nativeBoolToBooleanObject(lvalue < rvalue)
+	case token.GT:
+		return nativeBoolToBooleanObject(lvalue > rvalue)
+	case token.LT_EQ:
+		return nativeBoolToBooleanObject(lvalue <= rvalue)
+	case token.GT_EQ:
+		return nativeBoolToBooleanObject(lvalue >= rvalue)
+	case token.EQ:
+		return nativeBoolToBooleanObject(lvalue == rvalue)
+	case token.NOT_EQ:
+		return nativeBoolToBooleanObject(lvalue != rvalue)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+}
+
+func evalArrayInfixExpression(op token.TokenType, left, right object.Object) object.Object {
+
+	switch op {
+	case token.EQ:
+		return nativeBoolToBooleanObject(objectsEqual(left, right))
+	case token.NOT_EQ:
+		return nativeBoolToBooleanObject(!objectsEqual(left, right))
 	default:
 		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
 	}
 }
 
+/*
+* Equality by value for numbers, strings and arrays (element by element),
+* by identity for the other objects
+ */
+func objectsEqual(left, right object.Object) bool {
+	return valuesEqual(left, right, map[[2]*object.Array]bool{})
+}
+
+/*
+* comparing holds the pairs of arrays being compared. Arrays can contain
+* themselves, a pair met again inside its own comparison is taken as equal:
+* any difference is found by the comparison already running
+ */
+func valuesEqual(left, right object.Object, comparing map[[2]*object.Array]bool) bool {
+
+	switch {
+	case isNumber(left) && isNumber(right):
+		if left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ {
+			return left.(*object.Integer).Value == right.(*object.Integer).Value
+		}
+		return toFloat(left) == toFloat(right)
+
+	case left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
+		return left.(*object.String).Value == right.(*object.String).Value
+
+	case left.Type() == object.ARRAY_OBJ && right.Type() == object.ARRAY_OBJ:
+		larray, rarray := left.(*object.Array), right.(*object.Array)
+		pair := [2]*object.Array{larray, rarray}
+		if larray == rarray || comparing[pair] {
+			return true
+		}
+
+		if len(larray.Elements) != len(rarray.Elements) {
+			return false
+		}
+
+		comparing[pair] = true
+		defer delete(comparing, pair)
+
+		for i := range larray.Elements {
+			if !valuesEqual(larray.Elements[i], rarray.Elements[i], comparing) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return left == right
+}
+
+/*
+* arr[i] = v updates the array in place, every binding holding the array
+* sees the change. The index must be within the array bounds
+* hash[k] = v adds or updates the key, in place too
+ */
+func evalIndexAssignExpression(exp *ast.AssignExpression, target *ast.IndexExpression, env *object.Environment) object.Object {
+	left := Eval(target.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+
+	index := Eval(target.Index, env)
+	if isAbrupt(index) {
+		return index
+	}
+
+	val := Eval(exp.Value, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	if hash, ok := left.(*object.Hash); ok {
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+
+		if op, ok := compoundOperators[exp.Token.Type]; ok {
+			val = evalInfixOperator(op, evalHashIndexExpression(hash, index), val)
+			if isAbrupt(val) {
+				return val
+			}
+		}
+
+		hash.Set(key, val)
+		return val
+	}
+
+	arrayOb, ok := left.(*object.Array)
+	if !ok {
+		return newError("index assignment not supported: %s", left.Type())
+	}
+
+	indxOb, ok := index.(*object.Integer)
+	if !ok {
+		return newError("Invalid index")
+	}
+
+	idx, inRange := resolveIndex(indxOb.Value, len(arrayOb.Elements))
+	if !inRange {
+		return newError("index out of range: %d (length %d)", indxOb.Value, len(arrayOb.Elements))
+	}
+
+	if op, ok := compoundOperators[exp.Token.Type]; ok {
+		val = evalInfixOperator(op, arrayOb.Elements[idx], val)
+		if isAbrupt(val) {
+			return val
+		}
+	}
+
+	arrayOb.Elements[idx] = val
+
+	return val
+}
+
 func evalIntegerInfixExpression(op token.TokenType, left, right object.Object) object.Object {
 
 	rvalue := right.(*object.Integer).Value
@@ -283,6 +908,70 @@ func evalIntegerInfixExpression(op token.TokenType, left, right object.Object) o
 
 	var value int64 = 0
 
+	switch op {
+	case token.PLUS:
+		value = lvalue + rvalue
+	case token.MINUS:
+		value = lvalue - rvalue
+	case token.ASTERISK:
+		value = lvalue * rvalue
+	case token.SLASH, token.PERCENT:
+		if rvalue == 0 {
+			return newError("division by zero")
+		}
+		if op == token.SLASH {
+			value = lvalue / rvalue
+		} else {
+			value = lvalue % rvalue
+		}
+	case token.POWER:
+		//a negative exponent gives a fraction
+		if rvalue < 0 {
+			return &object.Float{Value: math.Pow(float64(lvalue), float64(rvalue))}
+		}
+		value = intPow(lvalue, rvalue)
+	case token.AMPERSAND:
+		value = lvalue & rvalue
+	case token.PIPE:
+		value = lvalue | rvalue
+	case token.CARET:
+		value = lvalue ^ rvalue
+	case token.SHL, token.SHR:
+		if rvalue < 0 {
+			return newError("negative shift count: %d", rvalue)
+		}
+		if op == token.SHL {
+			value = lvalue << uint64(rvalue)
+		} else {
+			value = lvalue >> uint64(rvalue)
+		}
+	case token.LT:
+		return nativeBoolToBooleanObject(lvalue < rvalue)
+	case token.GT:
+		return nativeBoolToBooleanObject(lvalue > rvalue)
+	case token.LT_EQ:
+		return nativeBoolToBooleanObject(lvalue <= rvalue)
+	case token.GT_EQ:
+		return nativeBoolToBooleanObject(lvalue >= rvalue)
+	case token.EQ:
+		return nativeBoolToBooleanObject(lvalue == rvalue)
+	case token.NOT_EQ:
+		return nativeBoolToBooleanObject(lvalue != rvalue)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
+	}
+
+	return &object.Integer{Value: value}
+}
+
+// Mixed INTEGER and FLOAT operands are promoted to FLOAT
+func evalFloatInfixExpression(op token.TokenType, left, right object.Object) object.Object {
+
+	rvalue := toFloat(right)
+	lvalue := toFloat(left)
+
+	var value float64 = 0
+
 	switch op {
 	case token.PLUS:
 		value = lvalue + rvalue
@@ -292,10 +981,18 @@ func evalIntegerInfixExpression(op token.TokenType, left, right object.Object) o
 		value = lvalue * rvalue
 	case token.SLASH:
 		value = lvalue / rvalue
+	case token.PERCENT:
+		value = math.Mod(lvalue, rvalue)
+	case token.POWER:
+		value = math.Pow(lvalue, rvalue)
 	case token.LT:
 		return nativeBoolToBooleanObject(lvalue < rvalue)
 	case token.GT:
 		return nativeBoolToBooleanObject(lvalue > rvalue)
+	case token.LT_EQ:
+		return nativeBoolToBooleanObject(lvalue <= rvalue)
+	case token.GT_EQ:
+		return nativeBoolToBooleanObject(lvalue >= rvalue)
 	case token.EQ:
 		return nativeBoolToBooleanObject(lvalue == rvalue)
 	case token.NOT_EQ:
@@ -304,14 +1001,41 @@ func evalIntegerInfixExpression(op token.TokenType, left, right object.Object) o
 		return newError("unknown operator: %s %s %s", left.Type(), op, right.Type())
 	}
 
-	return &object.Integer{Value: value}
+	return &object.Float{Value: value}
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+// obj must be an INTEGER or a FLOAT
+func toFloat(obj object.Object) float64 {
+	if i, ok := obj.(*object.Integer); ok {
+		return float64(i.Value)
+	}
+
+	return obj.(*object.Float).Value
+}
+
+// base**exp for exp >= 0, by squaring. Overflows wrap around
+func intPow(base, exp int64) int64 {
+	var result int64 = 1
+	for exp > 0 {
+		if exp&1 == 1 {
+			result *= base
+		}
+		base *= base
+		exp >>= 1
+	}
+
+	return result
 }
 
 func evalPrefixExpression(exp *ast.PrefixExpression, env *object.Environment) object.Object {
 
 	right := Eval(exp.Right, env)
 
-	if isError(right) {
+	if isAbrupt(right) {
 		return right
 	}
 
@@ -321,6 +1045,11 @@ func evalPrefixExpression(exp *ast.PrefixExpression, env *object.Environment) ob
 
 	case token.MINUS:
 		return evalMinusOperatorExpression(right)
+
+	case token.TILDE:
+		if intObj, ok := right.(*object.Integer); ok {
+			return &object.Integer{Value: ^intObj.Value}
+		}
 	}
 
 	return newError("unknown operator: %s%s", exp.Token.Type, right.Type())
@@ -328,6 +1057,10 @@ func evalPrefixExpression(exp *ast.PrefixExpression, env *object.Environment) ob
 
 func evalMinusOperatorExpression(right object.Object) object.Object {
 
+	if f, ok := right.(*object.Float); ok {
+		return &object.Float{Value: -f.Value}
+	}
+
 	if right.Type() != object.INTEGER_OBJ {
 
 		return newError("unknown operator: -%s", right.Type())
@@ -363,6 +1096,11 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 
 	for _, statement := range stmts {
 
+		//export let x = ... binds x like a let, the module loader collects it
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			statement = export.Statement
+		}
+
 		result = Eval(statement, env)
 
 		switch result := result.(type) {
@@ -373,6 +1111,9 @@ func evalProgram(stmts []ast.Statement, env *object.Environment) object.Object {
 		case *object.Error:
 			return result
 
+		case *object.Break, *object.Continue:
+			return outsideLoopError(result)
+
 		}
 	}
 
@@ -385,9 +1126,10 @@ func evalBlockStatements(stmts []ast.Statement, env *object.Environment) object.
 	for _, statement := range stmts {
 		result = Eval(statement, env)
 
-		//Return or Error: bubble up
+		//Return, Error, Break or Continue: bubble up
 		if result != nil {
-			if result.Type() == object.ERROR_OBJ || result.Type() == object.RETURN_VALUE_OBJ {
+			switch result.Type() {
+			case object.ERROR_OBJ, object.RETURN_VALUE_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
 				return result
 			}
 		}
@@ -397,6 +1139,122 @@ func evalBlockStatements(stmts []ast.Statement, env *object.Environment) object.
 	return result
 }
 
+/*
+* The loop runs in Go, not through recursion, so the number of iterations
+* is not bounded by the stack. The body shares the enclosing environment,
+* a Return or an Error leaves the loop and bubbles up
+ */
+func evalWhileStatement(ws *ast.WhileStatement, env *object.Environment) object.Object {
+
+	for {
+		condition := Eval(ws.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+
+		if !isTruthy(condition) {
+			return NULL
+		}
+
+		if result, done := evalLoopBody(ws.Body, env); done {
+			return result
+		}
+	}
+}
+
+/*
+* Runs one iteration of a loop. done reports that the loop must stop,
+* result is then what the loop evaluates to: nil after a break, or the
+* Return or Error to bubble up. A continue just ends the iteration
+ */
+func evalLoopBody(body *ast.BlockStatement, env *object.Environment) (result object.Object, done bool) {
+
+	switch result := Eval(body, env).(type) {
+	case *object.Break:
+		return NULL, true
+	case *object.ReturnValue, *object.Error:
+		return result, true
+	}
+
+	return nil, false
+}
+
+/*
+* Every iteration runs the body in a fresh environment holding the loop
+* variables, so closures created in the body capture that iteration's values.
+* With one variable: array elements, string runes, hash keys, range values.
+* With two: (index, element), (index, rune), (key, value), (index, value).
+* Hashes are visited in insertion order
+ */
+func evalForStatement(fs *ast.ForStatement, env *object.Environment) object.Object {
+
+	iterable := Eval(fs.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	iteration := func(key, value object.Object) (object.Object, bool) {
+		loopEnv := object.ExtendEnvironment(env)
+		if len(fs.Vars) == 1 {
+			loopEnv.Set(fs.Vars[0].Value, value)
+		} else {
+			loopEnv.Set(fs.Vars[0].Value, key)
+			loopEnv.Set(fs.Vars[1].Value, value)
+		}
+
+		return evalLoopBody(fs.Body, loopEnv)
+	}
+
+	switch iterable := iterable.(type) {
+
+	case *object.Array:
+		for i, element := range iterable.Elements {
+			if result, done := iteration(&object.Integer{Value: int64(i)}, element); done {
+				return result
+			}
+		}
+
+	case *object.String:
+		for i, r := range []rune(iterable.Value) {
+			if result, done := iteration(&object.Integer{Value: int64(i)}, &object.String{Value: string(r)}); done {
+				return result
+			}
+		}
+
+	case *object.Hash:
+		for _, key := range iterable.Keys {
+			pair := iterable.Pairs[key]
+			value := pair.Value
+			if len(fs.Vars) == 1 {
+				value = pair.Key
+			}
+
+			if result, done := iteration(pair.Key, value); done {
+				return result
+			}
+		}
+
+	case *object.Range:
+		n, step := iterable.Start, iterable.Step
+		for i := int64(0); (step > 0 && n < iterable.End) || (step < 0 && n > iterable.End); i++ {
+			if result, done := iteration(&object.Integer{Value: i}, &object.Integer{Value: n}); done {
+				return result
+			}
+
+			//stop instead of wrapping around at the ends of int64
+			if (step > 0 && n > math.MaxInt64-step) || (step < 0 && n < math.MinInt64-step) {
+				break
+			}
+			n += step
+		}
+
+	default:
+		return newError("cannot iterate over %s", iterable.Type())
+	}
+
+	return NULL
+}
+
 func nativeBoolToBooleanObject(in bool) *object.Boolean {
 	if in {
 		return TRUE
@@ -412,3 +1270,15 @@ func isError(obj object.Object) bool {
 	return false
 
 }
+
+// An Error, Break or Continue: the expression being evaluated stops and
+// passes it up, like a statement in a block
+func isAbrupt(obj object.Object) bool {
+	if obj != nil {
+		switch obj.Type() {
+		case object.ERROR_OBJ, object.BREAK_OBJ, object.CONTINUE_OBJ:
+			return true
+		}
+	}
+	return false
+}
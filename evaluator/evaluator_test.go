@@ -26,7 +26,10 @@ func TestArrayIndexExpressions(t *testing.T) {
 		{`let i = 0; [1,2,3][i]`, 1},
 		{`let myArray = [1,2,3]; let i = myArray[0]; myArray[i]`, 2},
 		{`[2,3][3]`, nil},
-		{`[1,2,3][-1]`, nil},
+		{`[1,2,3][-1]`, 3},
+		{`[1,2,3][-3]`, 1},
+		{`[1,2,3][-4]`, nil},
+		{`[][0]`, nil},
 	}
 
 	for _, tt := range tests {
@@ -41,6 +44,119 @@ func TestArrayIndexExpressions(t *testing.T) {
 	}
 }
 
+func TestStringIndexExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc"[0]`, "a"},
+		{`"abc"[2]`, "c"},
+		{`"abc"[-1]`, "c"},
+		{`"abc"[-3]`, "a"},
+		{`"naïve"[2]`, "ï"},
+		{`"naïve"[-3]`, "ï"},
+		{`let s = "abc"; s[len(s) - 1]`, "c"},
+		{`"abc"[3]`, nil},
+		{`"abc"[-4]`, nil},
+		{`""[0]`, nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		expected, ok := tt.expected.(string)
+		if !ok {
+			testNullObject(t, evaluated)
+			continue
+		}
+
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+		if str.Value != expected {
+			t.Errorf("wrong value for %q. expected=%q, got=%q", tt.input, expected, str.Value)
+		}
+	}
+}
+
+func TestStrictIndexing(t *testing.T) {
+	strictEval := func(input string) object.Object {
+		env := object.NewEnvironmentWithOptions(object.Options{StrictIndexing: true})
+		return Eval(parser.New(lex.New(input)).ParseProgram(), env)
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`[1, 2][2]`, "index out of range: 2 (length 2)"},
+		{`[1, 2][-3]`, "index out of range: -3 (length 2)"},
+		{`"naïve"[5]`, "index out of range: 5 (length 5)"},
+		{`""[-1]`, "index out of range: -1 (length 0)"},
+		//functions are evaluated with the options of their program
+		{`let get = fn(a, i) { a[i] }; get([1], 1)`, "index out of range: 1 (length 1)"},
+	}
+
+	for _, tt := range tests {
+		evaluated := strictEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+
+	testIntegerObject(t, strictEval(`[1, 2][-1]`), 2)
+	testNullObject(t, strictEval(`[].first()`))
+	//off unless asked for
+	testNullObject(t, testEval(`[1, 2][2]`))
+}
+
+func TestCyclicInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"let a = [1]; a[0] = a; a", "[[...]]"},
+		{"let a = [1]; a.push(a); a", "[1, [...]]"},
+		{`let h = {}; h["self"] = h; h`, "{self: {...}}"},
+		{`let a = []; let h = {"a": a}; a.push(h); a`, "[{a: [...]}]"},
+		//shared, not cyclic: printed in full
+		{"let b = [1]; [b, b]", "[[1], [1]]"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect() for %q. expected=%q, got=%q", tt.input, tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestCyclicEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"let a = [1]; a[0] = a; a == a", true},
+		{"let a = [1]; a[0] = a; a != a", false},
+		{"let a = [1]; a[0] = a; let b = [1]; b[0] = b; a == b", true},
+		{"let a = [1]; a.push(a); let b = [2]; b.push(b); a == b", false},
+		{"let a = [1]; a.push(a); let b = [1]; b.push(a); a == b", true},
+		{"let a = [1]; a.push(a); [a].contains(a)", true},
+		{"let a = [1]; a.push(a); a == [1, 2]", false},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
 func TestArrayLiterals(t *testing.T) {
 	input := "[1, 2*2, 3+3]"
 
@@ -70,6 +186,7 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`len("")`, 0},
 		{`len("four")`, 4},
 		{`len("hello world")`, 11},
+		{`len("naïve")`, 5},
 		{`len(1)`, "argument to `len` not suported, got INTEGER"},
 		{`len("one", "two")`, "wrong number of arguments. got=2, want=1"},
 	}
@@ -146,6 +263,46 @@ func TestStringConcat(t *testing.T) {
 
 }
 
+func TestStringInterpolation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`let a = 2; let b = 3; "total: ${a + b}"`, "total: 5"},
+		{`"${1.5} ${true} ${[1, "x"]}"`, "1.5 true [1, x]"},
+		{`let name = "monkey"; "hello ${name}!"`, "hello monkey!"},
+		{`"outer ${"inner ${1 + 1}"}"`, "outer inner 2"},
+		{`"\${x}"`, "${x}"},
+		{`let f = fn(x) { x * 2 }; "${f(21)}"`, "42"},
+	}
+
+	for _, tt := range tests {
+		obj := testEval(tt.input)
+		s, ok := obj.(*object.String)
+
+		if !ok {
+			t.Fatalf("object is  not *object.String. got=%T(%+v)", obj, obj)
+		}
+
+		if s.Value != tt.expected {
+			t.Fatalf("object.Value wrong. got=%q, expected=%q", s.Value, tt.expected)
+		}
+	}
+}
+
+func TestStringInterpolationError(t *testing.T) {
+	evaluated := testEval(`"value: ${x}"`)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+	}
+
+	if errObj.Inspect() != "Error: 1:11: identifier not found: x" {
+		t.Errorf("wrong error. got=%q", errObj.Inspect())
+	}
+}
+
 func TestFunctionObject(t *testing.T) {
 	input := "fn(x){x+2;};"
 	obj := testEval(input)
@@ -248,127 +405,203 @@ func TestLetStatement(t *testing.T) {
 	}
 }
 
-func TestErrorHandling(t *testing.T) {
+func TestAssignExpression(t *testing.T) {
 	tests := []struct {
-		input           string
-		expectedMessage string
+		input    string
+		expected int64
 	}{
-		{
-			"5+true;",
-			"type mismatch: INTEGER + BOOLEAN",
-		},
-		{
-			"5+true;5;",
-			"type mismatch: INTEGER + BOOLEAN",
-		},
-		{
-			"-true;5;",
-			"unknown operator: -BOOLEAN",
-		},
-		{
-			"true + false;",
-			"unknown operator: BOOLEAN + BOOLEAN",
-		},
-		{
-			"5;true + false;5",
-			"unknown operator: BOOLEAN + BOOLEAN",
-		},
-		{
-			"if(10>1){true + false;}",
-			"unknown operator: BOOLEAN + BOOLEAN",
-		},
+		{"let x = 1; x = 5; x", 5},
+		{"let x = 1; x = 5", 5},
+		{"let x = 1; let y = 2; x = y = 7; x + y", 14},
+		{"let x = 10; x += 5; x", 15},
+		{"let x = 10; x -= 5; x", 5},
+		{"let x = 10; x *= 5; x", 50},
+		{"let x = 10; x /= 5; x", 2},
+		{"let x = 1; let f = fn() { x = 2 }; f(); x", 2},
+		//operands are evaluated left to right
+		{"let x = 1; (x = 2) + x", 4},
+		{"let x = 1; x + (x = 2)", 3},
+		{"let x = 1; (x = 10) - (x = 3)", 7},
+		{"let a = [1, 2]; a.pop() * 10 + a.len()", 21},
+		//a parameter shadows the outer binding
+		{"let x = 1; let f = fn(x) { x = 2 }; f(0); x", 1},
 		{`
-          if(10>1){
-		    if(10>1){
-			   false + true;
-			  }
-			  return 1;
-		  }
-		`,
-			"unknown operator: BOOLEAN + BOOLEAN",
-		},
-		{
-			"foobar",
-			"identifier not found: foobar",
-		},
-		{
-			`"foo" - "bar"`,
-			"unknown operator: STRING - STRING",
-		},
+		let newCounter = fn() {
+			let count = 0;
+			fn() { count += 1 }
+		};
+		let c = newCounter();
+		c(); c();
+		let d = newCounter();
+		d();
+		c()
+		`, 3},
+		{`
+		let sum = 0;
+		let add = fn(n) { if (n > 0) { sum += n; add(n - 1) } };
+		add(4);
+		sum
+		`, 10},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-
-		errObj, ok := evaluated.(*object.Error)
-		if !ok {
-			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
-			continue
-		}
-
-		if errObj.Message != tt.expectedMessage {
-			t.Errorf("wrong error message. expected=%q, got%q", tt.expectedMessage, errObj.Message)
-		}
+		testIntegerObject(t, evaluated, tt.expected)
 	}
 }
 
-func TestReturnStatement(t *testing.T) {
+func TestIndexAssignExpression(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected int64
+		expected interface{}
 	}{
-		{"5*5; return 10; 4 ", 10},
-		{"return 0; 4 ", 0},
-		{"return 2*4; 4 ", 8},
+		{"let a = [1, 2, 3]; a[0] = 5; a", []int64{5, 2, 3}},
+		{"let a = [1, 2, 3]; a[2] = 5", 5},
+		{"let a = [1, 2, 3]; a[-1] = 5; a[-2] += 1; a", []int64{1, 3, 5}},
+		{"let a = [1, 2, 3]; a[1] += 10; a", []int64{1, 12, 3}},
+		{"let a = [1, 2, 3]; a[1] *= 3; a[1] -= 1; a", []int64{1, 5, 3}},
+		{"let a = [1, 2, 3]; let i = 0; a[i + 1] = a[i]; a", []int64{1, 1, 3}},
+		{"let a = [[1], [2]]; a[1][0] = 7; a[1]", []int64{7}},
+		//arrays are shared between bindings
+		{"let a = [1, 2]; let b = a; b[0] = 9; a", []int64{9, 2}},
+		{"let a = [1, 2]; let set = fn(arr) { arr[1] = 0 }; set(a); a", []int64{1, 0}},
 		{`
-          if(10>1){
-		    if(10>1){
-			  return 10;
-			  }
-			  return 1;
-		  }
-		`,
+		let squares = [0, 0, 0, 0];
+		let fill = fn(i) { if (i < 4) { squares[i] = i * i; fill(i + 1) } };
+		fill(0);
+		squares
+		`, []int64{0, 1, 4, 9}},
+	}
 
-			10},
-		{`
-          if(10>1){
-		    if(10>1){
-    		    if(10>1){
-				     return 10;
-				  }
-				  return 2;
-			  }
-			  return 1;
-		  }
-		`,
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
 
-			10},
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		}
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"[1, 2, 3, 4, 5][1:3]", []int64{2, 3}},
+		{"[1, 2, 3, 4, 5][:2]", []int64{1, 2}},
+		{"[1, 2, 3, 4, 5][3:]", []int64{4, 5}},
+		{"[1, 2, 3, 4, 5][:]", []int64{1, 2, 3, 4, 5}},
+		{"[1, 2, 3, 4, 5][-2:]", []int64{4, 5}},
+		{"[1, 2, 3, 4, 5][:-2]", []int64{1, 2, 3}},
+		{"[1, 2, 3, 4, 5][::2]", []int64{1, 3, 5}},
+		{"[1, 2, 3, 4, 5][1::2]", []int64{2, 4}},
+		{"[1, 2, 3, 4, 5][::-1]", []int64{5, 4, 3, 2, 1}},
+		{"[1, 2, 3, 4, 5][3:0:-1]", []int64{4, 3, 2}},
+		{"[1, 2, 3, 4, 5][-1:-4:-2]", []int64{5, 3}},
+		{"[1, 2, 3, 4, 5][-100:100]", []int64{1, 2, 3, 4, 5}},
+		{"[1, 2, 3, 4, 5][100:-100:-1]", []int64{5, 4, 3, 2, 1}},
+		{"[1, 2, 3, 4, 5][3:1]", []int64{}},
+		{"[][1:]", []int64{}},
+		{"let i = 1; [1, 2, 3][i:i + 1]", []int64{2}},
+		//slices are copies
+		{"let a = [1, 2, 3]; let b = a[:]; b[0] = 9; a", []int64{1, 2, 3}},
+		{`"hello"[1:3]`, "el"},
+		{`"hello"[::-1]`, "olleh"},
+		{`"hello"[-3:]`, "llo"},
+		{`"naïve"[1:4]`, "aïv"},
+		{`""[:]`, ""},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		testIntegerObject(t, evaluated, tt.expected)
+
+		switch expected := tt.expected.(type) {
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+			}
+		}
 	}
 }
 
-func TestIfElseExpression(t *testing.T) {
+func TestHashLiterals(t *testing.T) {
+	input := `let two = "two";
+	{
+		"one": 10 - 9,
+		two: 1 + 1,
+		"thr" + "ee": 6 / 2,
+		4: 4,
+		true: 5,
+		false: 6
+	}`
+
+	evaluated := testEval(input)
+	result, ok := evaluated.(*object.Hash)
+	if !ok {
+		t.Fatalf("Eval didn't return Hash. got=%T (%+v)", evaluated, evaluated)
+	}
+
+	expected := map[object.HashKey]int64{
+		(&object.String{Value: "one"}).HashKey():   1,
+		(&object.String{Value: "two"}).HashKey():   2,
+		(&object.String{Value: "three"}).HashKey(): 3,
+		(&object.Integer{Value: 4}).HashKey():      4,
+		TRUE.HashKey():                             5,
+		FALSE.HashKey():                            6,
+	}
+
+	if len(result.Pairs) != len(expected) {
+		t.Fatalf("Hash has wrong num of pairs. got=%d", len(result.Pairs))
+	}
+
+	for expectedKey, expectedValue := range expected {
+		pair, ok := result.Pairs[expectedKey]
+		if !ok {
+			t.Errorf("no pair for given key in Pairs")
+			continue
+		}
+
+		testIntegerObject(t, pair.Value, expectedValue)
+	}
+
+	expectedInspect := `{one: 1, two: 2, three: 3, 4: 4, true: 5, false: 6}`
+	if result.Inspect() != expectedInspect {
+		t.Errorf("wrong Inspect. expected=%q, got=%q", expectedInspect, result.Inspect())
+	}
+}
+
+func TestHashIndexExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
 		expected interface{}
 	}{
-		{"if(true){10}", 10},
-		{"if(false){10}", nil},
-		{"if(1){10}", 10},
-		{"if(1<2){10}", 10},
-		{"if(1>2){10}", nil},
-		{"if(1>2){10}else{20}", 20},
-		{"if(1<2){10}else{20}", 10},
+		{`{"foo": 5}["foo"]`, 5},
+		{`{"foo": 5}["bar"]`, nil},
+		{`let key = "foo"; {"foo": 5}[key]`, 5},
+		{`{}["foo"]`, nil},
+		{`{5: 5}[5]`, 5},
+		{`{true: 5}[true]`, 5},
+		{`{false: 5}[false]`, 5},
+		{`let h = {}; h["a"] = 1; h["b"] = 2; h["a"]`, 1},
+		{`let h = {"n": 1}; h["n"] += 41; h["n"]`, 42},
+		//hashes are shared between bindings
+		{`let h = {}; let g = h; g[1] = 7; h[1]`, 7},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		integer, ok := tt.expected.(int)
 
+		integer, ok := tt.expected.(int)
 		if ok {
 			testIntegerObject(t, evaluated, int64(integer))
 		} else {
@@ -377,22 +610,802 @@ func TestIfElseExpression(t *testing.T) {
 	}
 }
 
-func TestBangOperator(t *testing.T) {
+func TestHashInspectOrder(t *testing.T) {
+	evaluated := testEval(`let h = {"b": 1, "a": 2}; h["c"] = 3; h["b"] = 4; h`)
+
+	expected := `{b: 4, a: 2, c: 3}`
+	if evaluated.Inspect() != expected {
+		t.Errorf("wrong Inspect. expected=%q, got=%q", expected, evaluated.Inspect())
+	}
+}
+
+func testIntegerArray(t *testing.T, obj object.Object, expected []int64) bool {
+	result, ok := obj.(*object.Array)
+	if !ok {
+		t.Fatalf("object is not Array. got=%T (%+v)", obj, obj)
+		return false
+	}
+
+	if len(result.Elements) != len(expected) {
+		t.Fatalf("array has wrong num of elements. expected=%d, got=%d", len(expected), len(result.Elements))
+		return false
+	}
+
+	for i, e := range expected {
+		if !testIntegerObject(t, result.Elements[i], e) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestMemberExpressions(t *testing.T) {
 	tests := []struct {
 		input    string
-		expected bool
+		expected interface{}
 	}{
-		{"!true", false},
-		{"!false", true},
-		{"!5", false},
-		{"!!5", true},
-		{"!!false", false},
-		{"!!true", true},
+		{`{"foo": 5}.foo`, 5},
+		{`{"foo": 5}.bar`, nil},
+		{`let h = {"a": {"b": 2}}; h.a.b`, 2},
+		{`let h = {}; h.x = 1; h.x += 41; h["x"]`, 42},
+		{`let h = {"n": [1, 2]}; h.n[1] = 3; h.n[1]`, 3},
+		{`let h = {"f": fn(x) { x * 2 }}; h.f(21)`, 42},
+		//a field holding a function shadows the method of the same name
+		{`let h = {"len": fn() { 7 }}; h.len()`, 7},
+		//a field that is not a function does not hide the method
+		{`{"len": 5}.len()`, 1},
+		{`let f = fn(h) { h.f }; {"f": 3}.f()`, 3},
 	}
 
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
-		testBooleanObject(t, evaluated, tt.expected)
+
+		integer, ok := tt.expected.(int)
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestMethodCalls(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"abc".upper()`, "ABC"},
+		{`"ABC".lower()`, "abc"},
+		{`"  a b ".trim()`, "a b"},
+		{`"naïve".len()`, 5},
+		{`"a,b,c".split(",").join("-")`, "a-b-c"},
+		{`"abc".contains("bc")`, true},
+		{`"abc".contains("x")`, false},
+		{`"héllo".upper()[1]`, "É"},
+		{`[1, 2, 3].len()`, 3},
+		{`let a = [1]; a.push(2).push(3); a`, []int64{1, 2, 3}},
+		{`let a = [1, 2]; let last = a.pop(); [last, a.len()]`, []int64{2, 1}},
+		{`[].pop()`, nil},
+		{`[1, 2, 3].first()`, 1},
+		{`[1, 2, 3].last()`, 3},
+		{`[].last()`, nil},
+		{`[1, "a"].contains("a")`, true},
+		{`[1, 2].contains(3)`, false},
+		{`[1, "a", true].join(", ")`, "1, a, true"},
+		{`{"a": 1, "b": 2}.len()`, 2},
+		{`{"a": 1, "b": 2}.keys().join("")`, "ab"},
+		{`{"a": 1, "b": 2}.values()`, []int64{1, 2}},
+		{`{1: 1}.has(1)`, true},
+		{`{1: 1}.has(2)`, false},
+		//a user function taking the receiver first
+		{`let double = fn(x) { x * 2 }; 21.double()`, 42},
+		{`let add = fn(x, y) { x + y }; 1.add(2).add(3)`, 6},
+		{`let shout = fn(s) { s.upper() + "!" }; "hi".shout()`, "HI!"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+
+	//too few arguments for the function, counting the receiver
+	for _, input := range []string{
+		"let f = fn(a, b) { a }; 1.f()",
+		`let h = {"f": fn(a, b) { a }}; h.f(1)`,
+	} {
+		evaluated := testEval(input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", input, evaluated, evaluated)
+			continue
+		}
+		if errObj.Message != "wrong number of arguments. got=1, want=2" {
+			t.Errorf("wrong error message for %q. got=%q", input, errObj.Message)
+		}
+	}
+}
+
+func TestErrorHandling(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{
+			"5+true;",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"5+true;5;",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"-true;5;",
+			"unknown operator: -BOOLEAN",
+		},
+		{
+			"true + false;",
+			"unknown operator: BOOLEAN + BOOLEAN",
+		},
+		{
+			"5;true + false;5",
+			"unknown operator: BOOLEAN + BOOLEAN",
+		},
+		{
+			"if(10>1){true + false;}",
+			"unknown operator: BOOLEAN + BOOLEAN",
+		},
+		{`
+          if(10>1){
+		    if(10>1){
+			   false + true;
+			  }
+			  return 1;
+		  }
+		`,
+			"unknown operator: BOOLEAN + BOOLEAN",
+		},
+		{
+			"foobar",
+			"identifier not found: foobar",
+		},
+		{
+			`"foo" - "bar"`,
+			"unknown operator: STRING - STRING",
+		},
+		{
+			"[1] < [2]",
+			"unknown operator: ARRAY < ARRAY",
+		},
+		{
+			"1 << -1",
+			"negative shift count: -1",
+		},
+		{
+			"1 >> -2",
+			"negative shift count: -2",
+		},
+		{
+			"5 / 0",
+			"division by zero",
+		},
+		{
+			"5 % 0",
+			"division by zero",
+		},
+		{
+			"1.5 & 1",
+			"unknown operator: FLOAT & INTEGER",
+		},
+		{
+			"~true",
+			"unknown operator: ~BOOLEAN",
+		},
+		{
+			"~1.5",
+			"unknown operator: ~FLOAT",
+		},
+		{
+			"x = 5",
+			"assignment to undeclared variable: x",
+		},
+		{
+			"x += 5",
+			"assignment to undeclared variable: x",
+		},
+		{
+			"let f = fn() { y = 1 }; f()",
+			"assignment to undeclared variable: y",
+		},
+		{
+			`let s = "a"; s -= "b"`,
+			"unknown operator: STRING - STRING",
+		},
+		{
+			"let x = 1; x += true",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"let a = [1, 2]; a[2] = 0",
+			"index out of range: 2 (length 2)",
+		},
+		{
+			"let a = [1, 2]; a[-3] = 0",
+			"index out of range: -3 (length 2)",
+		},
+		{
+			`let a = [1, 2]; a["x"] = 0`,
+			"Invalid index",
+		},
+		{
+			`let s = "ab"; s[0] = "c"`,
+			"index assignment not supported: STRING",
+		},
+		{
+			`let a = ["x"]; a[0] += 1`,
+			"type mismatch: STRING + INTEGER",
+		},
+		{
+			"b[0] = 1",
+			"identifier not found: b",
+		},
+		{
+			"true && undefined",
+			"identifier not found: undefined",
+		},
+		{
+			"true <= false",
+			"unknown operator: BOOLEAN <= BOOLEAN",
+		},
+		{
+			`{"name": "Monkey"}[fn(x) { x }];`,
+			"unusable as hash key: FUNCTION",
+		},
+		{
+			`{[1]: 2}`,
+			"unusable as hash key: ARRAY",
+		},
+		{
+			`let h = {}; h[[]] = 1`,
+			"unusable as hash key: ARRAY",
+		},
+		{
+			"while (x) { 1 }",
+			"identifier not found: x",
+		},
+		{
+			"for (x in 5) { x }",
+			"cannot iterate over INTEGER",
+		},
+		{
+			"break; 5",
+			"break outside loop",
+		},
+		{
+			"[1, 2][::0]",
+			"slice step cannot be zero",
+		},
+		{
+			`[1, 2]["a":]`,
+			"slice index must be INTEGER, got STRING",
+		},
+		{
+			"5[1:]",
+			"slice operator not supported: INTEGER",
+		},
+		{
+			"match (3) { 1 => 1, 2 => 2 }",
+			"no match arm for value: 3",
+		},
+		{
+			`match ([1]) { [x] if x + true => 1 }`,
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			`match (nope) { _ => 1 }`,
+			"identifier not found: nope",
+		},
+		{
+			"if (true) { continue }",
+			"continue outside loop",
+		},
+		{
+			"let f = fn() { break }; while (true) { f() }",
+			"break outside loop",
+		},
+		{
+			"for (x in [1, 2]) { x + true }",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"range(1, 2, 0)",
+			"range step cannot be zero",
+		},
+		{
+			`range("a")`,
+			"argument to `range` not supported, got STRING",
+		},
+		{
+			"let i = 0; while (true) { i += 1; if (i > 2) { i + true } }",
+			"type mismatch: INTEGER + BOOLEAN",
+		},
+		{
+			"let f = fn(a, b) { a }; f(1)",
+			"wrong number of arguments. got=1, want=2",
+		},
+		{
+			"let f = fn() { 1 }; f(1)",
+			"wrong number of arguments. got=1, want=0",
+		},
+		{
+			"let f = fn() { while (false) { 1 } }; f() + 1",
+			"type mismatch: NULL + INTEGER",
+		},
+		{
+			"let f = fn() { for (x in [1]) { x } }; f() + 1",
+			"type mismatch: NULL + INTEGER",
+		},
+		{
+			`"abc".x`,
+			"member access not supported: STRING",
+		},
+		{
+			`"abc".reverse()`,
+			"unknown method: STRING.reverse",
+		},
+		{
+			"let x = 1; 2.x()",
+			"unknown method: INTEGER.x",
+		},
+		{
+			"let f = fn() { 1 }; 2.f()",
+			"unknown method: INTEGER.f",
+		},
+		{
+			`"abc".upper(1)`,
+			"wrong number of arguments. got=1, want=0",
+		},
+		{
+			`"a b".split(1)`,
+			"argument to `split` not supported, got INTEGER",
+		},
+		{
+			`{"a": 1}.a()`,
+			"not a function: INTEGER",
+		},
+		{
+			`{}.has([1])`,
+			"unusable as hash key: ARRAY",
+		},
+		{
+			"let a = [1]; a.x = 1",
+			"member assignment not supported: ARRAY",
+		},
+		{
+			`let h = {"n": "a"}; h.n += 1`,
+			"type mismatch: STRING + INTEGER",
+		},
+		{
+			"nope.len()",
+			"identifier not found: nope",
+		},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expectedMessage {
+			t.Errorf("wrong error message. expected=%q, got%q", tt.expectedMessage, errObj.Message)
+		}
+	}
+}
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"foobar", "Error: 1:1: identifier not found: foobar"},
+		{"let x = 1;\nx + true", "Error: 2:1: type mismatch: INTEGER + BOOLEAN"},
+		{"let f = fn() {\n  -true\n};\nf()", "Error: 2:3: unknown operator: -BOOLEAN"},
+		{"len(1, 2)", "Error: 1:1: wrong number of arguments. got=2, want=1"},
+		{"let f = fn() {\n  if (true) { break }\n};\nf()", "Error: 2:15: break outside loop"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Inspect() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errObj.Inspect())
+		}
+	}
+}
+
+func TestReturnStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"5*5; return 10; 4 ", 10},
+		{"return 0; 4 ", 0},
+		{"return 2*4; 4 ", 8},
+		{`
+          if(10>1){
+		    if(10>1){
+			  return 10;
+			  }
+			  return 1;
+		  }
+		`,
+
+			10},
+		{`
+          if(10>1){
+		    if(10>1){
+    		    if(10>1){
+				     return 10;
+				  }
+				  return 2;
+			  }
+			  return 1;
+		  }
+		`,
+
+			10},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestIfElseExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"if(true){10}", 10},
+		{"if(false){10}", nil},
+		{"if(1){10}", 10},
+		{"if(1<2){10}", 10},
+		{"if(1>2){10}", nil},
+		{"if(1>2){10}else{20}", 20},
+		{"if(1<2){10}else{20}", 10},
+		{"if(1>2){10}else if(2>1){20}else{30}", 20},
+		{"if(1>2){10}else if(2>3){20}else{30}", 30},
+		{"if(1>2){10}else if(2>3){20}", nil},
+		{"let x = 3; if(x==1){10}else if(x==2){20}else if(x==3){30}else{40}", 30},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		integer, ok := tt.expected.(int)
+
+		if ok {
+			testIntegerObject(t, evaluated, int64(integer))
+		} else {
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let i = 0; while (i < 10) { i += 1 }; i", 10},
+		{"let i = 0; while (false) { i += 1 }; i", 0},
+		{"let i = 0; while (i < 10) { i += 1 }", nil},
+		//a function ending with a loop returns null
+		{"let f = fn() { let i = 0; while (i < 3) { i += 1 } }; f()", nil},
+		{"let f = fn() { while (false) { 1 } }; if (f()) { 1 } else { 2 }", 2},
+		{"let i = 5; while (i > 0 && i != 2) { i -= 1 }; i", 2},
+		{"let f = fn() { let i = 0; while (true) { if (i == 3) { return i } i += 1 } }; f()", 3},
+		{"let sum = 0; let i = 0; while (i < 10000) { sum += i; i += 1 }; sum", 49995000},
+		{`
+		let arr = [1, 2, 3, 4];
+		let i = 0;
+		while (i < len("four")) { arr[i] *= 2; i += 1 };
+		arr
+		`, []int64{2, 4, 6, 8}},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case []int64:
+			testIntegerArray(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"let sum = 0; for (x in [1, 2, 3]) { sum += x }; sum", 6},
+		{"let sum = 0; for (i, x in [1, 2, 3]) { sum += i * x }; sum", 8},
+		{"let sum = 0; for (x in []) { sum += x }; sum", 0},
+		{`let s = ""; for (c in "naïve") { s = c + s }; s`, "evïan"},
+		{`let s = ""; for (i, c in "ab") { s += "${i}${c}" }; s`, "0a1b"},
+		{`let s = ""; for (k in {"b": 1, "a": 2, "c": 3}) { s += k }; s`, "bac"},
+		{`let s = ""; for (k, v in {"b": 1, "a": 2}) { s += "${k}=${v};" }; s`, "b=1;a=2;"},
+		{"let sum = 0; for (i in range(5)) { sum += i }; sum", 10},
+		{"let sum = 0; for (i in range(2, 5)) { sum += i }; sum", 9},
+		{"let n = 0; for (i in range(10, 0, -3)) { n = n * 100 + i }; n", 10070401},
+		{"let n = 0; for (i, v in range(5, 8)) { n += i * v }; n", 20},
+		{"let n = 0; for (i in range(5, 0)) { n += 1 }; n", 0},
+		{"for (x in [1, 2]) { x }", nil},
+		//a function ending with a loop returns null when the loop runs out
+		{"let f = fn(a) { for (x in a) { if (x == 0) { return x } } }; f([1, 2])", nil},
+		{"let f = fn(a) { for (x in a) { if (x == 0) { break } } }; f([1, 0])", nil},
+		{"let has0 = fn(a) { for (x in a) { if (x == 0) { return true } } }; !has0([1, 2])", true},
+		{`let has0 = fn(a) { for (x in a) { if (x == 0) { return true } } }; if (has0([1, 2])) { "found" } else { "none" }`, "none"},
+		{"let f = fn() { for (x in []) { x } }; [f()].len()", 1},
+		{"let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x * 10 } } }; f()", 20},
+		//loop variables are not visible after the loop
+		{"let x = 1; for (x in [5, 6]) { x }; x", 1},
+		//each iteration gets a fresh binding
+		{`
+		let fns = [fn() { 0 }, fn() { 0 }, fn() { 0 }];
+		for (i in range(3)) { fns[i] = fn() { i * 10 } };
+		fns[0]() + fns[1]() + fns[2]()
+		`, 30},
+		{"let big = 9223372036854775806; let n = 0; for (i in range(big - 2, big + 1, 2)) { n += 1 }; n", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			str, ok := evaluated.(*object.String)
+			if !ok {
+				t.Errorf("object is not String. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if str.Value != expected {
+				t.Errorf("String has wrong value. expected=%q, got=%q", expected, str.Value)
+			}
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestBreakContinue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"let i = 0; while (true) { if (i == 5) { break } i += 1 }; i", 5},
+		{"let n = 0; let i = 0; while (i < 10) { i += 1; if (i % 2 == 0) { continue } n += i }; n", 25},
+		{"let n = 0; for (x in [1, 2, 3, 4]) { if (x == 3) { break } n += x }; n", 3},
+		{"let n = 0; for (x in range(10)) { if (x % 3 != 0) { continue } n += x }; n", 18},
+		{`let n = 0; for (k, v in {"a": 1, "b": 2, "c": 3}) { if (k == "b") { continue } n += v }; n`, 4},
+		//break leaves the innermost loop only
+		{"let n = 0; for (i in range(3)) { for (j in range(3)) { if (j == 1) { break } n += 1 } }; n", 3},
+		{"let f = fn() { let i = 0; while (true) { i += 1; if (i == 4) { break } }; i * 10 }; f()", 40},
+		{"let f = fn() { for (x in [1, 2, 3]) { if (x == 2) { return x } continue } }; f()", 2},
+		//break and continue inside expressions leave the expression too
+		{"let i = 0; while (i < 3) { i += 1; let x = if (true) { break; }; }; i", 1},
+		{"let n = 0; for (x in [1, 2, 3]) { n += 10 * (if (x == 2) { continue } else { x }) }; n", 40},
+		{"let n = 0; for (x in [1, 2, 3]) { n = [n, if (x == 3) { break } else { x }][0] + x }; n", 3},
+		{"let n = 0; for (x in [1, 2, 3]) { quote(unquote(if (x == 2) { continue } else { x })); n += x }; n", 4},
+		{"let n = 0; for (x in [1, 2, 3]) { quote(unquote(if (x == 2) { break } else { x })); n += x }; n", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	input := "let r = []; for (x in [1, 2, 3]) { r.push(if (x == 2) { continue; } else { x }) }; r"
+	testIntegerArray(t, testEval(input), []int64{1, 3})
+}
+
+func TestConditionalExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"true ? 1 : 2", 1},
+		{"false ? 1 : 2", 2},
+		{"1 < 2 ? 10 : 20", 10},
+		{"let x = 5; x > 3 ? x * 2 : x", 10},
+		{"let a = 2; a == 1 ? 10 : a == 2 ? 20 : 30", 20},
+		{"[1 > 2 ? 1 : 2, 3][0]", 2},
+		//only the chosen branch runs
+		{"let n = 0; true ? (n += 1) : (n += 10); n", 1},
+		{"let n = 0; false ? (n += 1) : (n += 10); n", 10},
+		{"true ? 1 : undefined", 1},
+		{"false ? 1 / 0 : 3", 3},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testIntegerObject(t, evaluated, tt.expected)
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	describe := `
+	let describe = fn(v) {
+		match (v) {
+			0 => "zero",
+			-1 => "minus one",
+			1.5 => "one and a half",
+			"hi" => "greeting",
+			true => "yes",
+			[] => "empty",
+			[x] => "one: ${x}",
+			[x, x2] if x == x2 => "pair of ${x}",
+			[a, b, c] if a > b => "descending",
+			[first, ...rest] => "first ${first}, rest ${rest}",
+			{"type": "point", "x": px, "y": py} => "point ${px},${py}",
+			{"type": t} => "a ${t}",
+			_ => "other",
+		}
+	};
+	`
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"describe(0)", "zero"},
+		{"describe(-1)", "minus one"},
+		{"describe(1.5)", "one and a half"},
+		{`describe("hi")`, "greeting"},
+		{"describe(true)", "yes"},
+		{"describe(false)", "other"},
+		{"describe([])", "empty"},
+		{"describe([7])", "one: 7"},
+		{"describe([3, 3])", "pair of 3"},
+		{"describe([3, 4])", "first 3, rest [4]"},
+		{"describe([1, 2, 3])", "first 1, rest [2, 3]"},
+		{`describe({"type": "point", "x": 1, "y": 2, "z": 3})`, "point 1,2"},
+		{`describe({"type": "circle"})`, "a circle"},
+		{`describe({"kind": "circle"})`, "other"},
+		{"describe([3, 2, 1])", "descending"},
+		{`describe("bye")`, "other"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(describe + tt.input)
+
+		str, ok := evaluated.(*object.String)
+		if !ok {
+			t.Errorf("%s: object is not String. got=%T (%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+		if str.Value != tt.expected {
+			t.Errorf("%s: wrong value. expected=%q, got=%q", tt.input, tt.expected, str.Value)
+		}
+	}
+}
+
+func TestMatchBindings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"match (5) { x => x * 2 }", 10},
+		{"match ([1, [2, 3]]) { [a, [b, c]] => a + b + c }", 6},
+		{"match ([1, 2, 3]) { [_, ...rest] => len(\"${rest}\") }", 6},
+		{"let x = 1; match (2) { x => x }; x", 1},
+		//bindings of a failed arm don't leak into the next one
+		{"let x = 1; match ([5, 6]) { [x, 0] => 0, [_, y] => x + y }", 7},
+		{"let n = 0; match (1) { 1 => n += 1, 1 => n += 10 }; n", 1},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestLogicalOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"true && true", true},
+		{"true && false", false},
+		{"false && true", false},
+		{"false || true", true},
+		{"false || false", false},
+		{"true || false", true},
+		{"1 && 2", 2},
+		{"0 || 2", 0},
+		{`false || "a"`, "a"},
+		{"if (false) { 1 } || 3", 3},
+		{"if (false) { 1 } && 3", nil},
+		{"1 < 2 && 2 < 3", true},
+		{"1 > 2 || 2 > 3", false},
+		//short-circuit: the right operand is not evaluated
+		{"false && undefined", false},
+		{"true || undefined", true},
+		{"let f = fn() { 1 + true }; false && f()", false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			s, ok := evaluated.(*object.String)
+			if !ok || s.Value != expected {
+				t.Errorf("expected string %q. got=%T(%+v)", expected, evaluated, evaluated)
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestBangOperator(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"!true", false},
+		{"!false", true},
+		{"!5", false},
+		{"!!5", true},
+		{"!!false", false},
+		{"!!true", true},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		testBooleanObject(t, evaluated, tt.expected)
 	}
 }
 
@@ -421,6 +1434,28 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1<2) == false", false},
 		{"(1>2) == false", true},
 		{"(1>2) == true", false},
+		{"1 <= 1", true},
+		{"1 <= 0", false},
+		{"1 >= 1", true},
+		{"0 >= 1", false},
+		{"1.5 <= 2", true},
+		{"2 >= 2.5", false},
+		{`"a" < "b"`, true},
+		{`"b" < "a"`, false},
+		{`"abc" > "abd"`, false},
+		{`"ab" < "abc"`, true},
+		{`"a" <= "a"`, true},
+		{`"b" >= "c"`, false},
+		{`"a" == "a"`, true},
+		{`"a" != "a"`, false},
+		{`"Z" < "a"`, true},
+		{"[1, 2] == [1, 2]", true},
+		{"[1, 2] == [2, 1]", false},
+		{"[1, 2] != [1, 2, 3]", true},
+		{"[] == []", true},
+		{`[1, "a", [true]] == [1.0, "a", [true]]`, true},
+		{`[[1], "a"] == [[2], "a"]`, false},
+		{"let a = [1]; a == a", true},
 	}
 
 	for _, tt := range tests {
@@ -476,6 +1511,23 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3  + 10", 37},
 		{"3 * (3 * 3)  + 10", 37},
 		{"(5 + 10 * 2  + 15/3)*2 + -10", 50},
+		{"7 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 ** 10", 1024},
+		{"2 ** 3 ** 2", 512},
+		{"-2 ** 2", -4},
+		{"(-2) ** 3", -8},
+		{"5 ** 0", 1},
+		{"0xf0 & 0x3c", 0x30},
+		{"0xf0 | 0x0f", 0xff},
+		{"0xff ^ 0x0f", 0xf0},
+		{"~0", -1},
+		{"~5", -6},
+		{"1 << 10", 1024},
+		{"1024 >> 3", 128},
+		{"-16 >> 2", -4},
+		{"1 << 64", 0},
+		{"1 + 2 << 1", 6},
 	}
 
 	for _, tt := range tests {
@@ -484,6 +1536,122 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"1.5", 1.5},
+		{"-2.5", -2.5},
+		{"1.5 + 1.5", 3.0},
+		{"1 + 0.5", 1.5},
+		{"0.5 * 4", 2.0},
+		{"7 / 2.0", 3.5},
+		{"7 / 2", 3},
+		{"2e3 - 1", 1999.0},
+		{"1 < 1.5", true},
+		{"2.0 > 3", false},
+		{"1 == 1.0", true},
+		{"1.5 != 1.5", false},
+		{"int(2.9)", 2},
+		{"int(-2.9)", -2},
+		{`int("0x10")`, 16},
+		{`int("-0x10")`, -16},
+		{`int("755")`, 755},
+		{`int("+1_000")`, 1000},
+		{`int("0")`, 0},
+		{"float(3)", 3.0},
+		{`float("1.25")`, 1.25},
+		{"float(1.5)", 1.5},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		switch expected := tt.expected.(type) {
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		}
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5", "1.5"},
+		{"3.0", "3.0"},
+		{"2e10", "2e+10"},
+		{"1 / 0.0", "+Inf"},
+		{"7.5 % 2", "1.5"},
+		{"2 ** 0.5", "1.4142135623730951"},
+		{"2 ** -1", "0.5"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated.Inspect() != tt.expected {
+			t.Errorf("wrong Inspect(). expected=%q, got=%q", tt.expected, evaluated.Inspect())
+		}
+	}
+}
+
+func TestConversionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`int("abc")`, `cannot convert "abc" to INTEGER`},
+		{`int("0755")`, `cannot convert "0755" to INTEGER`},
+		{`int("08")`, `cannot convert "08" to INTEGER`},
+		{`int(" 1")`, `cannot convert " 1" to INTEGER`},
+		{`int("1.5")`, `cannot convert "1.5" to INTEGER`},
+		{`int("--1")`, `cannot convert "--1" to INTEGER`},
+		{`int("0x8000_0000_0000_0000")`, `cannot convert "0x8000_0000_0000_0000" to INTEGER`},
+		{`float("abc")`, `cannot convert "abc" to FLOAT`},
+		{`int(true)`, "argument to `int` not supported, got BOOLEAN"},
+		{`float(1, 2)`, "wrong number of arguments. got=2, want=1"},
+		{`int(1e300)`, "cannot convert 1e+300 to INTEGER"},
+		{`-1.5 + true`, "type mismatch: FLOAT + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T(%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, errObj.Message)
+		}
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+
+	result, ok := obj.(*object.Float)
+
+	if !ok {
+		t.Fatalf("object is  not *object.Float. got=%T(%+v)", obj, obj)
+		return false
+	}
+
+	if got := result.Value; got != expected {
+		t.Fatalf("Float.Value wrong. wanted=%g , got=%g", expected, got)
+		return false
+	}
+
+	return true
+}
+
 func testIntegerObject(t *testing.T, obj object.Object, expected int64) bool {
 
 	result, ok := obj.(*object.Integer)
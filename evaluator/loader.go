@@ -0,0 +1,189 @@
+package evaluator
+
+import (
+	"fmt"
+	"monkey/ast"
+	lex "monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"monkey/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Added to import paths without an extension
+const ModuleExtension = ".mk"
+
+// Modules loads the files named by import statements, bare names are
+// also looked up in the directories listed in MONKEYPATH
+var Modules = NewLoader(filepath.SplitList(os.Getenv("MONKEYPATH"))...)
+
+/*
+* A Loader evaluates every module once, into its own environment, and
+* caches the result by resolved path. A module importing itself, directly
+* or through other modules, is an import cycle and an error
+ */
+type Loader struct {
+	SearchPath []string
+
+	modules map[string]*object.Module
+	loading []string // the modules being evaluated, innermost last
+}
+
+func NewLoader(searchPath ...string) *Loader {
+	return &Loader{SearchPath: searchPath, modules: make(map[string]*object.Module)}
+}
+
+/*
+* Resolve finds the file for an import path.
+* ./ and ../ paths are relative to dir, the directory of the importing file.
+* Other relative paths are looked up in dir, then in the search path
+ */
+func (l *Loader) Resolve(path, dir string) (string, error) {
+	if filepath.Ext(path) == "" {
+		path += ModuleExtension
+	}
+
+	candidates := []string{}
+	switch {
+	case filepath.IsAbs(path):
+		candidates = append(candidates, path)
+	case strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../"):
+		candidates = append(candidates, filepath.Join(dir, path))
+	default:
+		candidates = append(candidates, filepath.Join(dir, path))
+		for _, searchDir := range l.SearchPath {
+			candidates = append(candidates, filepath.Join(searchDir, path))
+		}
+	}
+
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return filepath.Abs(candidate)
+		}
+	}
+
+	return "", fmt.Errorf("module not found: %s", path)
+}
+
+/*
+* Load returns the module for an import path, or an Error.
+* A module is evaluated with the options of the program importing it first
+ */
+func (l *Loader) Load(path, dir string, options object.Options) object.Object {
+	resolved, err := l.Resolve(path, dir)
+	if err != nil {
+		return newError("%s", err)
+	}
+
+	if module, ok := l.modules[resolved]; ok {
+		return module
+	}
+
+	for i, loading := range l.loading {
+		if loading == resolved {
+			cycle := append(append([]string{}, l.loading[i:]...), resolved)
+			return newError("import cycle: %s", strings.Join(cycle, " -> "))
+		}
+	}
+
+	l.loading = append(l.loading, resolved)
+	defer func() { l.loading = l.loading[:len(l.loading)-1] }()
+
+	module, errObj := l.evalModule(resolved, options)
+	if errObj != nil {
+		return errObj
+	}
+
+	l.modules[resolved] = module
+	return module
+}
+
+func (l *Loader) evalModule(path string, options object.Options) (*object.Module, object.Object) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, newError("cannot read module: %s", err)
+	}
+	defer file.Close()
+
+	p := parser.New(lex.NewFileReader(path, file))
+	program := p.ParseProgram()
+	if p.HasErrors() {
+		return nil, newError("cannot parse module %s: %s", path, strings.Join(p.Errors(), "; "))
+	}
+
+	macroEnv := object.NewEnvironmentWithOptions(options)
+	DefineMacros(program, macroEnv)
+	expanded, err := ExpandMacros(program, macroEnv)
+	if err != nil {
+		return nil, newError("%s", err)
+	}
+
+	env := object.NewEnvironmentWithOptions(options)
+	if result := Eval(expanded, env); isError(result) {
+		return nil, result
+	}
+
+	exports := object.NewHash()
+	for _, statement := range expanded.(*ast.Program).Statements {
+		if export, ok := statement.(*ast.ExportStatement); ok {
+			name := export.Statement.Name.Value
+			value, ok := env.Get(name)
+			if !ok {
+				//a top-level return stopped the module before the export
+				return nil, newError("module %s returned before export %s", path, name)
+			}
+			exports.Set(&object.String{Value: name}, value)
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return &object.Module{Name: name, Path: path, Exports: exports}, nil
+}
+
+/*
+* import binds the module under its alias or, without one, under its file
+* name. Relative paths are resolved from the directory of the importing file
+ */
+func evalImportStatement(is *ast.ImportStatement, env *object.Environment) object.Object {
+	dir := "."
+	if filename := is.Token.Pos.Filename; filename != "" {
+		dir = filepath.Dir(filename)
+	}
+
+	obj := Modules.Load(is.Path.Value, dir, env.Options())
+	if isError(obj) {
+		return obj
+	}
+	module := obj.(*object.Module)
+
+	name := module.Name
+	if is.Alias != nil {
+		name = is.Alias.Value
+	} else if !isIdentifier(name) {
+		return newError("module name %q is not an identifier, use: import %q as <name>", name, is.Path.Value)
+	}
+
+	env.Set(name, module)
+	return nil
+}
+
+func isIdentifier(name string) bool {
+	tok := lex.New(name).NextToken()
+	return tok.Type == token.IDENT && tok.Literal == name
+}
+
+func evalModuleIndexExpression(module *object.Module, index object.Object) object.Object {
+	name, ok := index.(*object.String)
+	if !ok {
+		return newError("module index must be a STRING, got %s", index.Type())
+	}
+
+	pair, ok := module.Exports.Pairs[name.HashKey()]
+	if !ok {
+		return newError("module %s does not export %s", module.Name, name.Value)
+	}
+
+	return pair.Value
+}
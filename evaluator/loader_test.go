@@ -0,0 +1,200 @@
+package evaluator
+
+import (
+	lex "monkey/lexer"
+	"monkey/object"
+	"monkey/parser"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writes the files under a temporary directory and returns it
+func testModuleDir(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+// evaluates input as if it was the file main.mk in dir, with a fresh loader
+func testEvalModule(dir, input string, searchPath ...string) object.Object {
+	return testEvalModuleWithOptions(dir, input, object.Options{}, searchPath...)
+}
+
+func testEvalModuleWithOptions(dir, input string, options object.Options, searchPath ...string) object.Object {
+	Modules = NewLoader(searchPath...)
+
+	l := lex.NewFile(filepath.Join(dir, "main.mk"), input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+	env := object.NewEnvironmentWithOptions(options)
+
+	return Eval(program, env)
+}
+
+func TestImport(t *testing.T) {
+	dir := testModuleDir(t, map[string]string{
+		"math.mk": `
+			let square = fn(x) { x * x };
+			export let pi = 3;
+			export let area = fn(r) { pi * square(r) };
+		`,
+		"lib/strings.mk": `
+			import "../math.mk";
+			export let describe = fn(r) { "area ${math["area"](r)}" };
+		`,
+		"twice.mk": `
+			let twice = macro(x) { quote(unquote(x) + unquote(x)) };
+			export let v = twice(21);
+		`,
+		"shared/counter.mk": `
+			export let counts = [0];
+		`,
+		"a.mk": `
+			import "shared/counter.mk";
+			counter["counts"][0] += 1;
+			export let counts = counter["counts"];
+		`,
+		"b.mk": `
+			import "shared/counter";
+			counter["counts"][0] += 10;
+			export let counts = counter["counts"];
+		`,
+	})
+
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`import "math.mk"; math["area"](2)`, 12},
+		{`import "./math"; math["pi"]`, 3},
+		{`import "math" as m; m["area"](1)`, 3},
+		{`import "math"; math.area(2) + math.pi`, 15},
+		{`import "math"; math.square(2)`, "module math does not export square"},
+		{`import "math"; math.pi = 1`, "member assignment not supported: MODULE"},
+		{`import "lib/strings.mk"; strings["describe"](1)`, "area 3"},
+		//macros are expanded in exported lets
+		{`import "twice"; twice.v`, 42},
+		//evaluated once: both importers share the same module
+		{`import "a"; import "b"; a["counts"][0]`, 11},
+		{`import "math"; import "math" as again; again["pi"] = 1; math["pi"]`, "index assignment not supported: MODULE"},
+		{`import "math"; math["square"]`, "module math does not export square"},
+		{`import "math"; math[1]`, "module index must be a STRING, got INTEGER"},
+		{`import "missing"`, "module not found: missing.mk"},
+		{`import "math"; square`, "identifier not found: square"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalModule(dir, tt.input)
+
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			switch obj := evaluated.(type) {
+			case *object.String:
+				if obj.Value != expected {
+					t.Errorf("wrong value. expected=%q, got=%q", expected, obj.Value)
+				}
+			case *object.Error:
+				if obj.Message != expected {
+					t.Errorf("wrong error message. expected=%q, got=%q", expected, obj.Message)
+				}
+			default:
+				t.Errorf("unexpected object for %q. got=%T (%+v)", tt.input, evaluated, evaluated)
+			}
+		}
+	}
+}
+
+func TestImportOptions(t *testing.T) {
+	dir := testModuleDir(t, map[string]string{
+		"list.mk": `export let at = fn(a, i) { a[i] };`,
+	})
+
+	testNullObject(t, testEvalModule(dir, `import "list"; list.at([1], 5)`))
+
+	evaluated := testEvalModuleWithOptions(dir, `import "list"; list.at([1], 5)`, object.Options{StrictIndexing: true})
+	if err, ok := evaluated.(*object.Error); !ok || err.Message != "index out of range: 5 (length 1)" {
+		t.Errorf("modules must use the options of the importer. got=%+v", evaluated)
+	}
+}
+
+func TestImportSearchPath(t *testing.T) {
+	lib := testModuleDir(t, map[string]string{
+		"util.mk": `export let answer = 42;`,
+	})
+	dir := testModuleDir(t, map[string]string{})
+
+	testIntegerObject(t, testEvalModule(dir, `import "util"; util["answer"]`, lib), 42)
+
+	evaluated := testEvalModule(dir, `import "./util"`, lib)
+	if err, ok := evaluated.(*object.Error); !ok || err.Message != "module not found: ./util.mk" {
+		t.Errorf("./ paths must not use the search path. got=%+v", evaluated)
+	}
+}
+
+func TestImportErrors(t *testing.T) {
+	dir := testModuleDir(t, map[string]string{
+		"a.mk":         `import "b"; export let x = 1;`,
+		"b.mk":         `import "c";`,
+		"c.mk":         `import "a";`,
+		"self.mk":      `import "self";`,
+		"broken.mk":    `let = 1;`,
+		"failing.mk":   "let x = 1;\nx + true;",
+		"my-lib.mk":    `export let x = 1;`,
+		"nested.mk":    `let f = fn() { export let x = 1; }; f();`,
+		"exporting.mk": `export let x = 1; export let y = x + 1;`,
+		"early.mk":     `export let a = 1; return 0; export let x = 1;`,
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`import "a"`, "import cycle: " + strings.Join([]string{
+			filepath.Join(dir, "a.mk"), filepath.Join(dir, "b.mk"), filepath.Join(dir, "c.mk"), filepath.Join(dir, "a.mk"),
+		}, " -> ")},
+		{`import "self"`, "import cycle: " + filepath.Join(dir, "self.mk") + " -> " + filepath.Join(dir, "self.mk")},
+		{`import "broken"`, "cannot parse module " + filepath.Join(dir, "broken.mk") + ": " +
+			filepath.Join(dir, "broken.mk") + ":1:5: Mismatch token[expected='IDENT', got='=']; " +
+			filepath.Join(dir, "broken.mk") + ":1:5: no prefix parse function for token `=` found"},
+		{`import "failing"`, "Error: " + filepath.Join(dir, "failing.mk") + ":2:1: type mismatch: INTEGER + BOOLEAN"},
+		{`import "my-lib"`, `module name "my-lib" is not an identifier, use: import "my-lib" as <name>`},
+		{`import "nested"`, "export is only allowed at the top level of a module"},
+		{`import "./early"; early.x + 1`, "module " + filepath.Join(dir, "early.mk") + " returned before export x"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEvalModule(dir, tt.input)
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned for %q. got=%T(%+v)", tt.input, evaluated, evaluated)
+			continue
+		}
+
+		message := errObj.Message
+		if strings.HasPrefix(tt.expected, "Error: ") {
+			message = errObj.Inspect()
+		}
+
+		if message != tt.expected {
+			t.Errorf("wrong error message. expected=%q, got=%q", tt.expected, message)
+		}
+	}
+
+	testIntegerObject(t, testEvalModule(dir, `import "my-lib" as lib; lib["x"]`), 1)
+	testIntegerObject(t, testEvalModule(dir, `import "exporting"; exporting["y"]`), 2)
+}
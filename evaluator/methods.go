@@ -0,0 +1,294 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"strings"
+	"unicode/utf8"
+)
+
+// A method gets the value it was called on, then the call arguments
+type method func(receiver object.Object, args ...object.Object) object.Object
+
+var methods = map[object.ObjectType]map[string]method{
+	object.STRING_OBJ: {
+		"len": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(utf8.RuneCountInString(receiver.(*object.String).Value))}
+		},
+		"upper": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToUpper(receiver.(*object.String).Value)}
+		},
+		"lower": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.ToLower(receiver.(*object.String).Value)}
+		},
+		"trim": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return &object.String{Value: strings.TrimSpace(receiver.(*object.String).Value)}
+		},
+		//s.split(sep), an empty sep splits into runes
+		"split": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 1); err != nil {
+				return err
+			}
+			sep, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `split` not supported, got %s", args[0].Type())
+			}
+
+			parts := strings.Split(receiver.(*object.String).Value, sep.Value)
+			elements := make([]object.Object, len(parts))
+			for i, part := range parts {
+				elements[i] = &object.String{Value: part}
+			}
+			return &object.Array{Elements: elements}
+		},
+		"contains": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 1); err != nil {
+				return err
+			}
+			sub, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `contains` not supported, got %s", args[0].Type())
+			}
+			return nativeBoolToBooleanObject(strings.Contains(receiver.(*object.String).Value, sub.Value))
+		},
+	},
+
+	object.ARRAY_OBJ: {
+		"len": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(len(receiver.(*object.Array).Elements))}
+		},
+		//appends in place, the array is returned: a.push(1).push(2)
+		"push": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 1); err != nil {
+				return err
+			}
+			array := receiver.(*object.Array)
+			array.Elements = append(array.Elements, args[0])
+			return array
+		},
+		//removes and returns the last element, NULL if empty
+		"pop": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			array := receiver.(*object.Array)
+			n := len(array.Elements)
+			if n == 0 {
+				return NULL
+			}
+			last := array.Elements[n-1]
+			array.Elements = array.Elements[:n-1]
+			return last
+		},
+		//first and last of an empty array are NULL, also with strict indexing
+		"first": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return evalIndexExpression(receiver, &object.Integer{Value: 0}, false)
+		},
+		"last": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return evalIndexExpression(receiver, &object.Integer{Value: -1}, false)
+		},
+		"contains": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 1); err != nil {
+				return err
+			}
+			for _, element := range receiver.(*object.Array).Elements {
+				if objectsEqual(element, args[0]) {
+					return TRUE
+				}
+			}
+			return FALSE
+		},
+		"join": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 1); err != nil {
+				return err
+			}
+			sep, ok := args[0].(*object.String)
+			if !ok {
+				return newError("argument to `join` not supported, got %s", args[0].Type())
+			}
+
+			parts := []string{}
+			for _, element := range receiver.(*object.Array).Elements {
+				parts = append(parts, element.Inspect())
+			}
+			return &object.String{Value: strings.Join(parts, sep.Value)}
+		},
+	},
+
+	object.HASH_OBJ: {
+		"len": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			return &object.Integer{Value: int64(len(receiver.(*object.Hash).Keys))}
+		},
+		"keys": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			hash := receiver.(*object.Hash)
+			keys := []object.Object{}
+			for _, key := range hash.Keys {
+				keys = append(keys, hash.Pairs[key].Key)
+			}
+			return &object.Array{Elements: keys}
+		},
+		"values": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 0); err != nil {
+				return err
+			}
+			hash := receiver.(*object.Hash)
+			values := []object.Object{}
+			for _, key := range hash.Keys {
+				values = append(values, hash.Pairs[key].Value)
+			}
+			return &object.Array{Elements: values}
+		},
+		"has": func(receiver object.Object, args ...object.Object) object.Object {
+			if err := checkArguments(args, 1); err != nil {
+				return err
+			}
+			key, ok := args[0].(object.Hashable)
+			if !ok {
+				return newError("unusable as hash key: %s", args[0].Type())
+			}
+			_, ok = receiver.(*object.Hash).Pairs[key.HashKey()]
+			return nativeBoolToBooleanObject(ok)
+		},
+	},
+}
+
+func checkArguments(args []object.Object, want int) object.Object {
+	if len(args) != want {
+		return newError("wrong number of arguments. got=%d, want=%d", len(args), want)
+	}
+	return nil
+}
+
+/*
+* obj.name reads the field of a hash, missing fields are NULL,
+* or the export of a module
+ */
+func evalMemberExpression(me *ast.MemberExpression, env *object.Environment) object.Object {
+	obj := Eval(me.Object, env)
+	if isAbrupt(obj) {
+		return obj
+	}
+
+	name := &object.String{Value: me.Property.Value}
+
+	switch obj := obj.(type) {
+	case *object.Hash:
+		return evalHashIndexExpression(obj, name)
+	case *object.Module:
+		return evalModuleIndexExpression(obj, name)
+	}
+
+	return newError("member access not supported: %s", obj.Type())
+}
+
+/*
+* value.name(args) calls, in order:
+* the function in the name field of a hash or exported by a module,
+* the name method of the value's type,
+* the user function name, with the value as its first argument.
+* A hash field that is not a function does not hide the method
+ */
+func evalMethodCall(me *ast.MemberExpression, arguments []ast.Expression, env *object.Environment) object.Object {
+	receiver := Eval(me.Object, env)
+	if isAbrupt(receiver) {
+		return receiver
+	}
+
+	args := evalExpressions(arguments, env)
+	if len(args) == 1 && isAbrupt(args[0]) {
+		return args[0]
+	}
+
+	name := me.Property.Value
+	var field object.Object
+
+	switch receiver := receiver.(type) {
+	case *object.Hash:
+		if pair, ok := receiver.Pairs[(&object.String{Value: name}).HashKey()]; ok {
+			switch pair.Value.(type) {
+			case *object.Function, *object.Builtin:
+				return applyFunction(pair.Value, args)
+			}
+			field = pair.Value
+		}
+	case *object.Module:
+		function := evalModuleIndexExpression(receiver, &object.String{Value: name})
+		if isAbrupt(function) {
+			return function
+		}
+		return applyFunction(function, args)
+	}
+
+	if method, ok := methods[receiver.Type()][name]; ok {
+		return method(receiver, args...)
+	}
+
+	if obj, ok := env.Get(name); ok {
+		if function, ok := obj.(*object.Function); ok && len(function.Parameters) > 0 {
+			return applyFunction(function, append([]object.Object{receiver}, args...))
+		}
+	}
+
+	if field != nil {
+		return applyFunction(field, args)
+	}
+
+	return newError("unknown method: %s.%s", receiver.Type(), name)
+}
+
+// obj.name = value sets a field of a hash
+func evalMemberAssignExpression(exp *ast.AssignExpression, target *ast.MemberExpression, env *object.Environment) object.Object {
+	obj := Eval(target.Object, env)
+	if isAbrupt(obj) {
+		return obj
+	}
+
+	val := Eval(exp.Value, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	hash, ok := obj.(*object.Hash)
+	if !ok {
+		return newError("member assignment not supported: %s", obj.Type())
+	}
+
+	name := &object.String{Value: target.Property.Value}
+
+	if op, ok := compoundOperators[exp.Token.Type]; ok {
+		val = evalInfixOperator(op, evalHashIndexExpression(hash, name), val)
+		if isAbrupt(val) {
+			return val
+		}
+	}
+
+	hash.Set(name, val)
+	return val
+}
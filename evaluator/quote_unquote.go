@@ -0,0 +1,88 @@
+package evaluator
+
+import (
+	"monkey/ast"
+	"monkey/object"
+	"monkey/token"
+)
+
+/*
+* quote(<expression>) returns the expression unevaluated, except for the
+* unquote(<expression>) calls inside it: those are evaluated and their
+* result spliced back into the tree
+ */
+func quote(node ast.Node, env *object.Environment) object.Object {
+	var err object.Object
+
+	node = ast.Modify(node, func(node ast.Node) ast.Node {
+		call, ok := node.(*ast.CallExpression)
+		if !ok || !isUnquoteCall(call) || err != nil {
+			return node
+		}
+
+		if len(call.Arguments) != 1 {
+			err = newError("wrong number of arguments. got=%d, want=1", len(call.Arguments))
+			return node
+		}
+
+		unquoted := Eval(call.Arguments[0], env)
+		if isAbrupt(unquoted) {
+			err = unquoted
+			return node
+		}
+
+		converted := convertObjectToASTNode(unquoted)
+		if converted == nil {
+			err = newError("cannot unquote %s", unquoted.Type())
+			return node
+		}
+
+		return converted
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return &object.Quote{Node: node}
+}
+
+func isQuoteCall(call *ast.CallExpression) bool {
+	function, ok := call.Function.(*ast.Identifier)
+	return ok && function.Value == "quote"
+}
+
+func isUnquoteCall(call *ast.CallExpression) bool {
+	function, ok := call.Function.(*ast.Identifier)
+	return ok && function.Value == "unquote"
+}
+
+// nil if the object has no literal form
+func convertObjectToASTNode(obj object.Object) ast.Node {
+
+	switch obj := obj.(type) {
+	case *object.Integer:
+		t := token.Token{Type: token.INT, Literal: obj.Inspect()}
+		return &ast.IntegerLiteral{Token: t, Value: obj.Value}
+
+	case *object.Float:
+		t := token.Token{Type: token.FLOAT, Literal: obj.Inspect()}
+		return &ast.FloatLiteral{Token: t, Value: obj.Value}
+
+	case *object.String:
+		t := token.Token{Type: token.STRING, Literal: obj.Value}
+		return &ast.StringLiteral{Token: t, Value: obj.Value}
+
+	case *object.Boolean:
+		t := token.Token{Type: token.FALSE, Literal: "false"}
+		if obj.Value {
+			t = token.Token{Type: token.TRUE, Literal: "true"}
+		}
+		return &ast.Boolean{Token: t, Value: obj.Value}
+
+	case *object.Quote:
+		return obj.Node
+	}
+
+	return nil
+}
@@ -1,51 +1,120 @@
 package lexer
 
 import (
+	"bufio"
+	"fmt"
+	"io"
 	"monkey/token"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 type Lexer struct {
-	input        string
-	position     int  // current position in the input(points to current char)
-	readPosition int  // current reading position in input (after current char)
-	ch           byte // current char under examination
+	reader    *bufio.Reader
+	filename  string
+	position  int             // current position in the input(points to current char)
+	ch        rune            // current char under examination
+	chWidth   int             // size in bytes of the current char, 0 at EOF
+	peek      rune            // next char, read only when needed
+	peekWidth int             // size in bytes of the next char, 0 at EOF, -1 if not read yet
+	line      int             // line of the current char
+	column    int             // column of the current char, counted in runes
+	text      strings.Builder // source text read since the start of the current token
+	interp    []int           // open ${...} in strings: depth of the { } nested in each
+	err       error           // read error, reported once as an ILLEGAL token
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
-	l.readChar()
+	return NewFile("", input)
+}
+
+// New lexer, tokens positions are reported within the given file name
+func NewFile(filename, input string) *Lexer {
+	return NewFileReader(filename, strings.NewReader(input))
+}
+
+// New lexer reading the program from r as it goes
+func NewReader(r io.Reader) *Lexer {
+	return NewFileReader("", r)
+}
+
+// New lexer reading from r, tokens positions are reported within the given file name
+func NewFileReader(filename string, r io.Reader) *Lexer {
+	l := &Lexer{reader: bufio.NewReader(r), filename: filename, line: 1, column: 1}
+	l.ch, l.chWidth = l.readRune()
+	l.peekWidth = -1
 	return l
 }
 
+// Next rune of the input, (0, 0) at EOF or on a read error
+func (l *Lexer) readRune() (rune, int) {
+	ch, width, err := l.reader.ReadRune()
+	if err != nil {
+		if err != io.EOF && l.err == nil {
+			l.err = err
+		}
+		return 0, 0
+	}
+
+	return ch, width
+}
+
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+	//already at EOF
+	if l.chWidth == 0 {
+		return
+	}
+
+	l.text.WriteRune(l.ch)
+
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
 	}
 
-	l.position = l.readPosition
-	l.readPosition++
+	l.position += l.chWidth
+	l.ch, l.chWidth = l.peekChar(), l.peekWidth
+	l.peekWidth = -1
+	l.column++
+}
+
+// Marks the start of a piece of source text, see textFrom
+func (l *Lexer) mark() int {
+	return l.text.Len()
+}
+
+// Source text read since the given mark
+func (l *Lexer) textFrom(mark int) string {
+	return l.text.String()[mark:]
+}
+
+// position of the current char
+func (l *Lexer) pos() token.Position {
+	return token.Position{Filename: l.filename, Offset: l.position, Line: l.line, Column: l.column}
 }
 
-func (l *Lexer) isPeekChar(char byte) bool {
+func (l *Lexer) isPeekChar(char rune) bool {
 	return char == l.peekChar()
 }
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		return 0
+// Reading ahead only on demand keeps the lexer from blocking on a
+// stream for a char it does not need yet
+func (l *Lexer) peekChar() rune {
+	if l.peekWidth < 0 {
+		l.peek, l.peekWidth = l.readRune()
 	}
 
-	return l.input[l.readPosition]
+	return l.peek
 }
 
 func (l *Lexer) readIdentifier() string {
-	position := l.position
-	for isLetter(l.ch) {
+	start := l.mark()
+	for isLetter(l.ch) || isIdentDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.textFrom(start)
 }
 
 func (l *Lexer) skipWhiteSpace() {
@@ -54,26 +123,315 @@ func (l *Lexer) skipWhiteSpace() {
 	}
 }
 
-func (l *Lexer) readNumber() string {
-	position := l.position
-	for isDigit(l.ch) {
+/*
+* Integer or float literal
+* e.g 5, 1.5, 2e10, 1.5E-3, 0xff, 0o17, 0b1010, 1_000_000
+* A '.' is part of the number only if a digit follows it
+* Underscores may only separate digits
+* A decimal integer does not start with 0: 0755 is malformed, use 0o755
+ */
+func (l *Lexer) readNumber() token.Token {
+	start := l.mark()
+	tokenType := token.TokenType(token.INT)
+
+	if l.ch == '0' && isBasePrefix(l.peekChar()) {
+		return l.readPrefixedInteger()
+	}
+
+	digits := l.readDigits()
+	valid := validDigits(digits)
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		valid = validDigits(l.readDigits()) && valid
+	}
+
+	if l.ch == 'e' || l.ch == 'E' {
+		tokenType = token.FLOAT
+		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+
+		valid = isDigit(l.ch) && validDigits(l.readDigits()) && valid
+	}
+
+	//e.g 12ab, 1_
+	if isLetter(l.ch) || isIdentDigit(l.ch) {
+		l.readIdentifier()
+		valid = false
+	}
+
+	if tokenType == token.INT && len(digits) > 1 && digits[0] == '0' {
+		valid = false
+	}
+
+	if !valid {
+		return token.Token{Type: token.ILLEGAL, Literal: "malformed number: " + l.textFrom(start)}
+	}
+
+	return token.Token{Type: tokenType, Literal: l.textFrom(start)}
+}
+
+// 0x, 0o and 0b integers
+func (l *Lexer) readPrefixedInteger() token.Token {
+	start := l.mark()
+
+	l.readChar() //skip 0
+	base := unicode.ToLower(l.ch)
+	l.readChar() //skip x, o or b
+
+	//read all the identifier chars so 0xfg is reported as a whole
+	digits := l.readIdentifier()
+
+	valid := digits != "" && !strings.HasSuffix(digits, "_") && !strings.Contains(digits, "__")
+	for _, ch := range digits {
+		if ch != '_' && !isBaseDigit(base, ch) {
+			valid = false
+		}
+	}
+
+	if !valid {
+		return token.Token{Type: token.ILLEGAL, Literal: "malformed number: " + l.textFrom(start)}
+	}
+
+	return token.Token{Type: token.INT, Literal: l.textFrom(start)}
+}
+
+// Reads decimal digits and underscores
+func (l *Lexer) readDigits() string {
+	start := l.mark()
+	for isDigit(l.ch) || l.ch == '_' {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.textFrom(start)
+}
+
+// Underscores must separate digits: no leading, trailing or doubled ones
+func validDigits(digits string) bool {
+	return digits != "" && !strings.HasPrefix(digits, "_") &&
+		!strings.HasSuffix(digits, "_") && !strings.Contains(digits, "__")
+}
+
+func isBasePrefix(ch rune) bool {
+	switch ch {
+	case 'x', 'X', 'o', 'O', 'b', 'B':
+		return true
+	}
+	return false
+}
+
+func isBaseDigit(base, ch rune) bool {
+	switch base {
+	case 'x':
+		return isHexDigit(ch)
+	case 'o':
+		return '0' <= ch && ch <= '7'
+	case 'b':
+		return ch == '0' || ch == '1'
+	}
+	return false
+}
+
+/*
+* Double quoted string, escape sequences are decoded:
+* \n \t \r \0 \" \\ \$ \u{1F600}
+* On a bad escape the rest of the string is skipped and an ILLEGAL token
+* describing the first error is returned
+*
+* Strings with embedded expressions, e.g "total: ${a + b}!", are split in
+* parts around the expression tokens:
+* TEMPLATE("total: ") a + b TEMPLATE_END("!")
+* A part followed by another embedded expression is a TEMPLATE too.
+* first is false when resuming the string after the } of an embedded expression
+ */
+func (l *Lexer) readString(first bool) token.Token {
+	var out strings.Builder
+	errMsg := ""
+
+	l.readChar() //skip opening " or the } closing the embedded expression
+	for !isStringQuote(l.ch) && !l.isInterpolation() {
+		if l.ch != '\\' {
+			out.WriteRune(l.ch)
+			l.readChar()
+			continue
+		}
+
+		ch, err := l.readEscape()
+		if err != "" && errMsg == "" {
+			errMsg = err
+		}
+		out.WriteRune(ch)
+	}
+
+	tokenType := token.TokenType(token.STRING)
+	switch {
+	case l.isInterpolation():
+		tokenType = token.TEMPLATE
+		l.interp = append(l.interp, 0)
+		l.readChar() //skip $
+	case l.ch != '"':
+		//validate closing " for the strings
+		return token.Token{Type: token.ILLEGAL, Literal: "unterminated string"}
+	case !first:
+		tokenType = token.TEMPLATE_END
+	}
+	l.readChar() //skip closing " or {
+
+	if errMsg != "" {
+		return token.Token{Type: token.ILLEGAL, Literal: errMsg}
+	}
+
+	return token.Token{Type: tokenType, Literal: out.String()}
+}
+
+// ${ starts an embedded expression
+func (l *Lexer) isInterpolation() bool {
+	return l.ch == '$' && l.isPeekChar('{')
 }
 
-func (l *Lexer) readString() string {
-	position := l.position
-	for !isStringQuote(l.ch) {
+// Reads an escape sequence, the current char is the backslash
+func (l *Lexer) readEscape() (rune, string) {
+	l.readChar() //skip \
+
+	var ch rune
+	switch l.ch {
+	case 'n':
+		ch = '\n'
+	case 't':
+		ch = '\t'
+	case 'r':
+		ch = '\r'
+	case '0':
+		ch = 0
+	case '"', '\\', '$':
+		ch = l.ch
+	case 'u':
+		return l.readUnicodeEscape()
+	case 0:
+		return 0, "unterminated string"
+	default:
+		ch = l.ch
 		l.readChar()
+		return ch, fmt.Sprintf("unknown escape sequence: \\%c", ch)
 	}
-	return l.input[position:l.position]
+
+	l.readChar()
+	return ch, ""
+}
+
+// \u{XXXX}: 1 to 6 hex digits of a valid code point
+func (l *Lexer) readUnicodeEscape() (rune, string) {
+	l.readChar() //skip u
+
+	if l.ch != '{' {
+		return utf8.RuneError, "invalid unicode escape: missing '{' after \\u"
+	}
+	l.readChar()
+
+	start := l.mark()
+	for isHexDigit(l.ch) {
+		l.readChar()
+	}
+	digits := l.textFrom(start)
+
+	if l.ch != '}' {
+		return utf8.RuneError, fmt.Sprintf("invalid unicode escape: \\u{%s", digits)
+	}
+	l.readChar()
+
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || len(digits) > 6 || !utf8.ValidRune(rune(value)) {
+		return utf8.RuneError, fmt.Sprintf("invalid unicode escape: \\u{%s}", digits)
+	}
+
+	return rune(value), ""
+}
+
+/*
+* Raw string, `...`
+* No escape sequences, may span several lines (heredoc)
+ */
+func (l *Lexer) readRawString() token.Token {
+	l.readChar() //skip opening `
+
+	start := l.mark()
+	for l.ch != '`' && l.ch != 0 {
+		l.readChar()
+	}
+
+	if l.ch != '`' {
+		return token.Token{Type: token.ILLEGAL, Literal: "unterminated raw string"}
+	}
+	literal := l.textFrom(start)
+	l.readChar() //skip closing `
+
+	return token.Token{Type: token.STRING, Literal: literal}
 }
 
 func (l *Lexer) NextToken() token.Token {
 
+	l.text.Reset()
 	l.skipWhiteSpace()
 
+	//comments are not tokens, they are attached to the next token
+	var comments []token.Comment
+	for l.isComment() {
+		pos := l.pos()
+		comment, ok := l.readComment()
+		if !ok {
+			return token.Token{Type: token.ILLEGAL, Literal: "unterminated comment", Pos: pos, End: l.pos(), Leading: comments}
+		}
+
+		comments = append(comments, comment)
+		l.skipWhiteSpace()
+	}
+
+	pos := l.pos()
+	tok := l.readToken()
+	tok.Pos = pos
+	tok.End = l.pos()
+	tok.Leading = comments
+
+	return tok
+}
+
+func (l *Lexer) isComment() bool {
+	return l.ch == '/' && (l.isPeekChar('/') || l.isPeekChar('*'))
+}
+
+// Line comment: from // up to the end of the line (newline not included)
+// Block comment: from /* up to the closing */, block comments don't nest
+func (l *Lexer) readComment() (token.Comment, bool) {
+	comment := token.Comment{Pos: l.pos()}
+	start := l.mark()
+
+	l.readChar() //skip /
+	if l.ch == '/' {
+		for l.ch != '\n' && l.ch != 0 {
+			l.readChar()
+		}
+	} else {
+		l.readChar() //skip *
+		for !(l.ch == '*' && l.isPeekChar('/')) {
+			if l.ch == 0 {
+				return comment, false
+			}
+			l.readChar()
+		}
+		l.readChar()
+		l.readChar()
+	}
+
+	comment.Text = l.textFrom(start)
+	comment.End = l.pos()
+
+	return comment, true
+}
+
+func (l *Lexer) readToken() token.Token {
+
 	var tok token.Token
 	switch l.ch {
 	case '=':
@@ -81,24 +439,81 @@ func (l *Lexer) NextToken() token.Token {
 			l.readChar()
 			literal := "=="
 			tok = token.Token{Type: token.EQ, Literal: literal}
+		} else if l.isPeekChar('>') {
+			l.readChar()
+			tok = token.Token{Type: token.ARROW, Literal: "=>"}
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		if l.isPeekChar('=') {
+			l.readChar()
+			tok = token.Token{Type: token.PLUS_ASSIGN, Literal: "+="}
+		} else {
+			tok = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		if l.isPeekChar('=') {
+			l.readChar()
+			tok = token.Token{Type: token.MINUS_ASSIGN, Literal: "-="}
+		} else {
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.isPeekChar('<') {
+			l.readChar()
+			tok = token.Token{Type: token.SHL, Literal: "<<"}
+		} else if l.isPeekChar('=') {
+			l.readChar()
+			tok = token.Token{Type: token.LT_EQ, Literal: "<="}
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.isPeekChar('>') {
+			l.readChar()
+			tok = token.Token{Type: token.SHR, Literal: ">>"}
+		} else if l.isPeekChar('=') {
+			l.readChar()
+			tok = token.Token{Type: token.GT_EQ, Literal: ">="}
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
+	case ':':
+		tok = newToken(token.COLON, l.ch)
+	case '?':
+		tok = newToken(token.QUESTION, l.ch)
+	case '.':
+		if l.isPeekChar('.') {
+			l.readChar()
+			if l.isPeekChar('.') {
+				l.readChar()
+				tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			} else {
+				tok = token.Token{Type: token.ILLEGAL, Literal: ".."}
+			}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '{':
+		if n := len(l.interp); n > 0 {
+			l.interp[n-1]++
+		}
 		tok = newToken(token.LBRACE, l.ch)
 	case '}':
+		n := len(l.interp)
+		if n > 0 && l.interp[n-1] == 0 {
+			//end of the embedded expression, back to the string
+			l.interp = l.interp[:n-1]
+			return l.readString(false)
+		}
+		if n > 0 {
+			l.interp[n-1]--
+		}
 		tok = newToken(token.RBRACE, l.ch)
 	case '(':
 		tok = newToken(token.LPAREN, l.ch)
@@ -116,19 +531,53 @@ func (l *Lexer) NextToken() token.Token {
 		} else {
 			tok = newToken(token.BANG, l.ch)
 		}
+	case '&':
+		if l.isPeekChar('&') {
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: "&&"}
+		} else {
+			tok = newToken(token.AMPERSAND, l.ch)
+		}
+	case '|':
+		if l.isPeekChar('|') {
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: "||"}
+		} else {
+			tok = newToken(token.PIPE, l.ch)
+		}
+	case '^':
+		tok = newToken(token.CARET, l.ch)
+	case '~':
+		tok = newToken(token.TILDE, l.ch)
+	case '%':
+		tok = newToken(token.PERCENT, l.ch)
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.isPeekChar('*') {
+			l.readChar()
+			tok = token.Token{Type: token.POWER, Literal: "**"}
+		} else if l.isPeekChar('=') {
+			l.readChar()
+			tok = token.Token{Type: token.ASTERISK_ASSIGN, Literal: "*="}
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
-	case '"':
-		l.readChar() //skip " char
-		tok.Type = token.STRING
-		tok.Literal = l.readString()
-		//validate closing " for the strings
-		if !(l.ch == '"') {
-			return token.Token{Type: token.ILLEGAL, Literal: string("invalid string")}
+		if l.isPeekChar('=') {
+			l.readChar()
+			tok = token.Token{Type: token.SLASH_ASSIGN, Literal: "/="}
+		} else {
+			tok = newToken(token.SLASH, l.ch)
 		}
+	case '"':
+		return l.readString(true)
+	case '`':
+		return l.readRawString()
 	case 0:
+		if l.err != nil {
+			tok = token.Token{Type: token.ILLEGAL, Literal: "read error: " + l.err.Error()}
+			l.err = nil
+			return tok
+		}
 		tok.Literal = ""
 		tok.Type = token.EOF
 	default:
@@ -138,9 +587,7 @@ func (l *Lexer) NextToken() token.Token {
 			tok.Type = token.LookupIndent(tok.Literal)
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = token.INT
-			return tok
+			return l.readNumber()
 		} else {
 			tok = newToken(token.ILLEGAL, l.ch)
 		}
@@ -150,22 +597,32 @@ func (l *Lexer) NextToken() token.Token {
 	return tok
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
+}
+
+// digits allowed inside an identifier (not as first char)
+func isIdentDigit(ch rune) bool {
+	return isDigit(ch) || ch >= utf8.RuneSelf && unicode.IsDigit(ch)
 }
 
-func isDigit(ch byte) bool {
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func isStringQuote(ch byte) bool {
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+func isStringQuote(ch rune) bool {
 	return ch == '"' || ch == 0 //EOF
 }
 
-func isWhiteLetter(ch byte) bool {
+func isWhiteLetter(ch rune) bool {
 	return ' ' == ch || ch == '\n' || ch == '\t' || ch == '\r'
 }
@@ -1,7 +1,11 @@
 package lexer
 
 import (
+	"errors"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"monkey/token"
 )
@@ -15,7 +19,7 @@ func TestNextToken(t *testing.T) {
     };
     
     let result = add(five, ten);
-    !-/*5;
+    !-/ *5;
     5 < 10 > 5;
 
     if (5 < 10){
@@ -26,6 +30,12 @@ func TestNextToken(t *testing.T) {
 
     10 == 10;
     10 != 9;
+    1 <= 2 >= 3;
+    a && b || c;
+    % ** & | ^ ~ << >>;
+    x += 1 -= 2 *= 3 /= 4;
+    a ? b : c;
+    match x => ...rest ..; a.b;
 	"this is a string"
 	""
 	[1,2];
@@ -114,6 +124,54 @@ func TestNextToken(t *testing.T) {
 		{token.NOT_EQ, "!="},
 		{token.INT, "9"},
 		{token.SEMICOLON, ";"},
+		{token.INT, "1"},
+		{token.LT_EQ, "<="},
+		{token.INT, "2"},
+		{token.GT_EQ, ">="},
+		{token.INT, "3"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.AND, "&&"},
+		{token.IDENT, "b"},
+		{token.OR, "||"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.PERCENT, "%"},
+		{token.POWER, "**"},
+		{token.AMPERSAND, "&"},
+		{token.PIPE, "|"},
+		{token.CARET, "^"},
+		{token.TILDE, "~"},
+		{token.SHL, "<<"},
+		{token.SHR, ">>"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.INT, "2"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.INT, "3"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.INT, "4"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.QUESTION, "?"},
+		{token.IDENT, "b"},
+		{token.COLON, ":"},
+		{token.IDENT, "c"},
+		{token.SEMICOLON, ";"},
+		{token.MATCH, "match"},
+		{token.IDENT, "x"},
+		{token.ARROW, "=>"},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.ILLEGAL, ".."},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "a"},
+		{token.DOT, "."},
+		{token.IDENT, "b"},
+		{token.SEMICOLON, ";"},
 
 		{token.STRING, "this is a string"},
 		{token.STRING, ""},
@@ -140,3 +198,412 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "let x = 5;\n  add(x, \"ab\")"
+
+	tests := []struct {
+		expectedType token.TokenType
+		expectedPos  string
+		expectedEnd  string
+	}{
+		{token.LET, "test.mk:1:1", "test.mk:1:4"},
+		{token.IDENT, "test.mk:1:5", "test.mk:1:6"},
+		{token.ASSIGN, "test.mk:1:7", "test.mk:1:8"},
+		{token.INT, "test.mk:1:9", "test.mk:1:10"},
+		{token.SEMICOLON, "test.mk:1:10", "test.mk:1:11"},
+		{token.IDENT, "test.mk:2:3", "test.mk:2:6"},
+		{token.LPAREN, "test.mk:2:6", "test.mk:2:7"},
+		{token.IDENT, "test.mk:2:7", "test.mk:2:8"},
+		{token.COMMA, "test.mk:2:8", "test.mk:2:9"},
+		{token.STRING, "test.mk:2:10", "test.mk:2:14"},
+		{token.RPAREN, "test.mk:2:14", "test.mk:2:15"},
+		{token.EOF, "test.mk:2:15", "test.mk:2:15"},
+		{token.EOF, "test.mk:2:15", "test.mk:2:15"},
+	}
+
+	l := NewFile("test.mk", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Pos.String() != tt.expectedPos {
+			t.Fatalf("tests[%d] - pos wrong. expected=%q, got=%q", i, tt.expectedPos, tok.Pos)
+		}
+
+		if tok.End.String() != tt.expectedEnd {
+			t.Fatalf("tests[%d] - end wrong. expected=%q, got=%q", i, tt.expectedEnd, tok.End)
+		}
+	}
+}
+
+func TestUnicodeInput(t *testing.T) {
+	input := "let naïve = \"שלום\"; größe2 + x1;\n€"
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedPos     string
+	}{
+		{token.LET, "let", "1:1"},
+		{token.IDENT, "naïve", "1:5"},
+		{token.ASSIGN, "=", "1:11"},
+		{token.STRING, "שלום", "1:13"},
+		{token.SEMICOLON, ";", "1:19"},
+		{token.IDENT, "größe2", "1:21"},
+		{token.PLUS, "+", "1:28"},
+		{token.IDENT, "x1", "1:30"},
+		{token.SEMICOLON, ";", "1:32"},
+		{token.ILLEGAL, "€", "2:1"},
+		{token.EOF, "", "2:2"},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - liteal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok.Pos.String() != tt.expectedPos {
+			t.Fatalf("tests[%d] - pos wrong. expected=%q, got=%q", i, tt.expectedPos, tok.Pos)
+		}
+	}
+}
+
+func TestStringLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{`"a\nb"`, token.STRING, "a\nb"},
+		{`"tab\there"`, token.STRING, "tab\there"},
+		{`"say \"hi\""`, token.STRING, `say "hi"`},
+		{`"back\\slash"`, token.STRING, `back\slash`},
+		{`"\r\0"`, token.STRING, "\r\x00"},
+		{`"\u{41}\u{5d0}\u{1F600}"`, token.STRING, "Aא😀"},
+		{"`raw \\n ${x}`", token.STRING, `raw \n ${x}`},
+		{"`line1\nline2`", token.STRING, "line1\nline2"},
+		{`"bad \q escape"`, token.ILLEGAL, `unknown escape sequence: \q`},
+		{`"\u41"`, token.ILLEGAL, `invalid unicode escape: missing '{' after \u`},
+		{`"\u{41"`, token.ILLEGAL, `invalid unicode escape: \u{41`},
+		{`"\u{110000}"`, token.ILLEGAL, `invalid unicode escape: \u{110000}`},
+		{`"\u{}"`, token.ILLEGAL, `invalid unicode escape: \u{}`},
+		{`"open`, token.ILLEGAL, "unterminated string"},
+		{`"open\`, token.ILLEGAL, "unterminated string"},
+		{"`open", token.ILLEGAL, "unterminated raw string"},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - liteal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after the string. got=%q", i, tok.Type)
+		}
+	}
+}
+
+func TestRawStringPositions(t *testing.T) {
+	l := New("`a\nbc` x")
+
+	l.NextToken()
+	tok := l.NextToken()
+
+	if tok.Pos.String() != "2:5" {
+		t.Fatalf("pos wrong. expected=%q, got=%q", "2:5", tok.Pos)
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `// leading
+let x = 5; // trailing
+/* block
+   comment */ x /**/ / 2 /* a */ /* b */
+// at the end`
+
+	tests := []struct {
+		expectedType     token.TokenType
+		expectedLiteral  string
+		expectedComments []string
+	}{
+		{token.LET, "let", []string{"// leading"}},
+		{token.IDENT, "x", nil},
+		{token.ASSIGN, "=", nil},
+		{token.INT, "5", nil},
+		{token.SEMICOLON, ";", nil},
+		{token.IDENT, "x", []string{"// trailing", "/* block\n   comment */"}},
+		{token.SLASH, "/", []string{"/**/"}},
+		{token.INT, "2", nil},
+		{token.EOF, "", []string{"/* a */", "/* b */", "// at the end"}},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - liteal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if len(tok.Leading) != len(tt.expectedComments) {
+			t.Fatalf("tests[%d] - wrong number of comments. expected=%d, got=%d", i, len(tt.expectedComments), len(tok.Leading))
+		}
+
+		for j, c := range tt.expectedComments {
+			if tok.Leading[j].Text != c {
+				t.Fatalf("tests[%d] - comment[%d] wrong. expected=%q, got=%q", i, j, c, tok.Leading[j].Text)
+			}
+		}
+	}
+}
+
+func TestCommentPositions(t *testing.T) {
+	l := New("x /* a\nb */ y")
+
+	l.NextToken()
+	tok := l.NextToken()
+
+	c := tok.Leading[0]
+	if c.Pos.String() != "1:3" || c.End.String() != "2:5" {
+		t.Fatalf("comment position wrong. got=%s-%s", c.Pos, c.End)
+	}
+
+	if tok.Pos.String() != "2:6" {
+		t.Fatalf("pos wrong. expected=%q, got=%q", "2:6", tok.Pos)
+	}
+}
+
+func TestUnterminatedComment(t *testing.T) {
+	l := New("x /* open")
+
+	l.NextToken()
+	tok := l.NextToken()
+
+	if tok.Type != token.ILLEGAL || tok.Literal != "unterminated comment" {
+		t.Fatalf("expected unterminated comment. got=%q(%q)", tok.Type, tok.Literal)
+	}
+
+	if tok.Pos.String() != "1:3" {
+		t.Fatalf("pos wrong. expected=%q, got=%q", "1:3", tok.Pos)
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{"5", token.INT, "5"},
+		{"1.5", token.FLOAT, "1.5"},
+		{"0.25", token.FLOAT, "0.25"},
+		{"2e10", token.FLOAT, "2e10"},
+		{"1.5E-3", token.FLOAT, "1.5E-3"},
+		{"3e+2", token.FLOAT, "3e+2"},
+		{"2e", token.ILLEGAL, "malformed number: 2e"},
+		{"1.5e-", token.ILLEGAL, "malformed number: 1.5e-"},
+		{"0xff", token.INT, "0xff"},
+		{"0XFF", token.INT, "0XFF"},
+		{"0x_ff", token.INT, "0x_ff"},
+		{"0o17", token.INT, "0o17"},
+		{"0b1010", token.INT, "0b1010"},
+		{"1_000_000", token.INT, "1_000_000"},
+		{"1_000.000_1", token.FLOAT, "1_000.000_1"},
+		{"0", token.INT, "0"},
+		{"08", token.ILLEGAL, "malformed number: 08"},
+		{"0755", token.ILLEGAL, "malformed number: 0755"},
+		{"0_1", token.ILLEGAL, "malformed number: 0_1"},
+		{"01.5", token.FLOAT, "01.5"},
+		{"0x", token.ILLEGAL, "malformed number: 0x"},
+		{"0xfg", token.ILLEGAL, "malformed number: 0xfg"},
+		{"0o18", token.ILLEGAL, "malformed number: 0o18"},
+		{"0b102", token.ILLEGAL, "malformed number: 0b102"},
+		{"0b1__0", token.ILLEGAL, "malformed number: 0b1__0"},
+		{"0x_", token.ILLEGAL, "malformed number: 0x_"},
+		{"1_", token.ILLEGAL, "malformed number: 1_"},
+		{"1__0", token.ILLEGAL, "malformed number: 1__0"},
+		{"1_.5", token.ILLEGAL, "malformed number: 1_.5"},
+		{"1e_5", token.ILLEGAL, "malformed number: 1e_5"},
+		{"12ab", token.ILLEGAL, "malformed number: 12ab"},
+	}
+
+	for i, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - liteal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+
+		if tok := l.NextToken(); tok.Type != token.EOF {
+			t.Fatalf("tests[%d] - expected EOF after the number. got=%q", i, tok.Type)
+		}
+	}
+}
+
+func TestReaderLexer(t *testing.T) {
+	input := `let naïve = fn(x) { x * 0x10 + 1.5e3 }; // comment
+	/* block */ "esc\t\u{5d0}" ` + "`raw\nstring`" + ` [1, 2][0] != 3 @`
+
+	readers := []io.Reader{
+		strings.NewReader(input),
+		iotest.OneByteReader(strings.NewReader(input)),
+		iotest.HalfReader(strings.NewReader(input)),
+	}
+
+	for _, r := range readers {
+		expected := New(input)
+		l := NewReader(r)
+
+		for i := 0; ; i++ {
+			want := expected.NextToken()
+			got := l.NextToken()
+
+			if got.Type != want.Type || got.Literal != want.Literal || got.Pos != want.Pos || got.End != want.End {
+				t.Fatalf("tokens[%d] differ. expected=%+v, got=%+v", i, want, got)
+			}
+
+			if len(got.Leading) != len(want.Leading) {
+				t.Fatalf("tokens[%d] comments differ. expected=%+v, got=%+v", i, want.Leading, got.Leading)
+			}
+
+			if got.Type == token.EOF {
+				break
+			}
+		}
+	}
+}
+
+func TestReaderLexerStreaming(t *testing.T) {
+	pr, pw := io.Pipe()
+	more := make(chan bool)
+
+	go func() {
+		pw.Write([]byte("let x = 5; "))
+		<-more
+		pw.Write([]byte("x"))
+		pw.Close()
+	}()
+
+	l := NewReader(pr)
+
+	//the first statement is available before the producer is done
+	for _, expected := range []token.TokenType{token.LET, token.IDENT, token.ASSIGN, token.INT, token.SEMICOLON} {
+		if tok := l.NextToken(); tok.Type != expected {
+			t.Fatalf("tokentype wrong. expected=%q, got=%q", expected, tok.Type)
+		}
+	}
+
+	more <- true
+
+	for _, expected := range []token.TokenType{token.IDENT, token.EOF} {
+		if tok := l.NextToken(); tok.Type != expected {
+			t.Fatalf("tokentype wrong. expected=%q, got=%q", expected, tok.Type)
+		}
+	}
+}
+
+func TestReaderLexerError(t *testing.T) {
+	r := io.MultiReader(strings.NewReader("x "), iotest.ErrReader(errors.New("broken pipe")))
+	l := NewFileReader("pipe", r)
+
+	tok := l.NextToken()
+	if tok.Type != token.IDENT {
+		t.Fatalf("tokentype wrong. expected=%q, got=%q", token.IDENT, tok.Type)
+	}
+
+	tok = l.NextToken()
+	if tok.Type != token.ILLEGAL || tok.Literal != "read error: broken pipe" {
+		t.Fatalf("expected read error. got=%q(%q)", tok.Type, tok.Literal)
+	}
+
+	if tok.Pos.String() != "pipe:1:3" {
+		t.Fatalf("pos wrong. expected=%q, got=%q", "pipe:1:3", tok.Pos)
+	}
+
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("tokentype wrong. expected=%q, got=%q", token.EOF, tok.Type)
+	}
+}
+
+func TestStringInterpolation(t *testing.T) {
+	input := `"total: ${a + b}!" "${x}${ {1: "${y}"} }" "\${no}" "$5"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.TEMPLATE, "total: "},
+		{token.IDENT, "a"},
+		{token.PLUS, "+"},
+		{token.IDENT, "b"},
+		{token.TEMPLATE_END, "!"},
+		{token.TEMPLATE, ""},
+		{token.IDENT, "x"},
+		{token.TEMPLATE, ""},
+		{token.LBRACE, "{"},
+		{token.INT, "1"},
+		{token.COLON, ":"},
+		{token.TEMPLATE, ""},
+		{token.IDENT, "y"},
+		{token.TEMPLATE_END, ""},
+		{token.RBRACE, "}"},
+		{token.TEMPLATE_END, ""},
+		{token.STRING, "${no}"},
+		{token.STRING, "$5"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokentype wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+
+		if tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - liteal wrong. expected=%q, got=%q", i, tt.expectedLiteral, tok.Literal)
+		}
+	}
+}
+
+func TestUnterminatedInterpolation(t *testing.T) {
+	l := New(`"a ${x} b`)
+
+	for _, expected := range []token.TokenType{token.TEMPLATE, token.IDENT, token.ILLEGAL, token.EOF} {
+		if tok := l.NextToken(); tok.Type != expected {
+			t.Fatalf("tokentype wrong. expected=%q, got=%q", expected, tok.Type)
+		}
+	}
+}
@@ -1,13 +1,18 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"monkey/object"
 	"monkey/repl"
 	"os"
 	"os/user"
 )
 
 func main() {
+	strict := flag.Bool("strict", false, "make an index out of range an error instead of null")
+	flag.Parse()
+
 	user, err := user.Current()
 	if err != nil {
 		panic(err)
@@ -15,5 +20,5 @@ func main() {
 	fmt.Printf("Hello %s, The Monkey programming language is here!", user.Username)
 	fmt.Printf(" Type a command...")
 
-	repl.Start(os.Stdin, os.Stdout)
+	repl.Start(os.Stdin, os.Stdout, object.Options{StrictIndexing: *strict})
 }
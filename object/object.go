@@ -3,7 +3,10 @@ package object
 import (
 	"bytes"
 	"fmt"
+	"hash/fnv"
 	"monkey/ast"
+	"monkey/token"
+	"strconv"
 	"strings"
 )
 
@@ -16,6 +19,7 @@ type Object interface {
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
@@ -24,19 +28,84 @@ const (
 	STRING_OBJ       = "STRING"
 	BUILTIN_OBJ      = "BUILTIN"
 	ARRAY_OBJ        = "ARRAY"
+	HASH_OBJ         = "HASH"
+	RANGE_OBJ        = "RANGE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
+	QUOTE_OBJ        = "QUOTE"
+	MACRO_OBJ        = "MACRO"
+	MODULE_OBJ       = "MODULE"
 )
 
+// Key of an object in a Hash
+type HashKey struct {
+	Type  ObjectType
+	Value uint64
+}
+
+// Objects usable as hash keys: Integer, String and Boolean
+type Hashable interface {
+	HashKey() HashKey
+}
+
+type HashPair struct {
+	Key   Object
+	Value Object
+}
+
+// Hash, the pairs are kept in insertion order
+type Hash struct {
+	Pairs map[HashKey]HashPair
+	Keys  []HashKey
+}
+
+func NewHash() *Hash {
+	return &Hash{Pairs: make(map[HashKey]HashPair)}
+}
+
+// Adds a pair or updates the value of an existing key (keeping its place)
+func (h *Hash) Set(key Hashable, value Object) {
+	hashKey := key.HashKey()
+	if _, ok := h.Pairs[hashKey]; !ok {
+		h.Keys = append(h.Keys, hashKey)
+	}
+
+	h.Pairs[hashKey] = HashPair{Key: key.(Object), Value: value}
+}
+
+func (h *Hash) Type() ObjectType { return HASH_OBJ }
+
+func (h *Hash) Inspect() string { return inspect(h, map[Object]bool{}) }
+
+func (h *Hash) inspect(seen map[Object]bool) string {
+	var out bytes.Buffer
+	pairs := []string{}
+	for _, key := range h.Keys {
+		pair := h.Pairs[key]
+		pairs = append(pairs, inspect(pair.Key, seen)+": "+inspect(pair.Value, seen))
+	}
+
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
+
+	return out.String()
+}
+
+// Arrays are shared, not copied: after let b = a; b[0] = 1 changes a too
 type Array struct {
 	Elements []Object
 }
 
 func (ar *Array) Type() ObjectType { return ARRAY_OBJ }
 
-func (ar *Array) Inspect() string {
+func (ar *Array) Inspect() string { return inspect(ar, map[Object]bool{}) }
+
+func (ar *Array) inspect(seen map[Object]bool) string {
 	var out bytes.Buffer
 	elements := []string{}
 	for _, e := range ar.Elements {
-		elements = append(elements, e.Inspect())
+		elements = append(elements, inspect(e, seen))
 	}
 
 	out.WriteString("[")
@@ -46,6 +115,47 @@ func (ar *Array) Inspect() string {
 	return out.String()
 }
 
+/*
+* Arrays and hashes can contain themselves: let a = [1]; a[0] = a.
+* seen holds the containers being printed, one met again inside itself
+* is printed as [...] or {...}
+ */
+func inspect(obj Object, seen map[Object]bool) string {
+	switch obj := obj.(type) {
+	case *Array:
+		if seen[obj] {
+			return "[...]"
+		}
+		seen[obj] = true
+		defer delete(seen, obj)
+		return obj.inspect(seen)
+
+	case *Hash:
+		if seen[obj] {
+			return "{...}"
+		}
+		seen[obj] = true
+		defer delete(seen, obj)
+		return obj.inspect(seen)
+	}
+
+	return obj.Inspect()
+}
+
+// The integers from Start up to, not including, End in steps of Step.
+// Nothing is allocated, the values are produced while iterating
+type Range struct {
+	Start int64
+	End   int64
+	Step  int64
+}
+
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+
+func (r *Range) Inspect() string {
+	return fmt.Sprintf("range(%d, %d, %d)", r.Start, r.End, r.Step)
+}
+
 type BuiltinFunction func(args ...Object) Object
 
 type Builtin struct {
@@ -78,12 +188,60 @@ func (f *Function) Inspect() string {
 	return out.String()
 }
 
+// An imported file: only its exported bindings are reachable
+type Module struct {
+	Name    string
+	Path    string // the resolved file path
+	Exports *Hash  // export name (a String) -> value
+}
+
+func (m *Module) Type() ObjectType { return MODULE_OBJ }
+func (m *Module) Inspect() string  { return "module " + m.Name + " (" + m.Path + ")" }
+
+// quote(<expression>): the expression itself, not evaluated
+type Quote struct {
+	Node ast.Node
+}
+
+func (q *Quote) Type() ObjectType { return QUOTE_OBJ }
+func (q *Quote) Inspect() string  { return "QUOTE(" + q.Node.String() + ")" }
+
+type Macro struct {
+	Parameters []*ast.Identifier
+	Body       *ast.BlockStatement
+	Env        *Environment
+}
+
+func (m *Macro) Type() ObjectType { return MACRO_OBJ }
+func (m *Macro) Inspect() string {
+	var out bytes.Buffer
+
+	params := []string{}
+	for _, p := range m.Parameters {
+		params = append(params, p.String())
+	}
+	out.WriteString("macro")
+	out.WriteString("(")
+	out.WriteString(strings.Join(params, ","))
+	out.WriteString("){\n")
+	out.WriteString(m.Body.String())
+	out.WriteString("}\n")
+	return out.String()
+}
+
 type Error struct {
 	Message string
+	Pos     token.Position //where the error occurred, if known
 }
 
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
-func (e *Error) Inspect() string  { return "Error: " + e.Message }
+func (e *Error) Inspect() string {
+	if e.Pos.IsValid() {
+		return "Error: " + e.Pos.String() + ": " + e.Message
+	}
+
+	return "Error: " + e.Message
+}
 
 //Return
 type ReturnValue struct {
@@ -93,6 +251,21 @@ type ReturnValue struct {
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 
+//Break and Continue unwind the statements up to the enclosing loop
+type Break struct {
+	Pos token.Position //the break statement
+}
+
+func (b *Break) Type() ObjectType { return BREAK_OBJ }
+func (b *Break) Inspect() string  { return "break" }
+
+type Continue struct {
+	Pos token.Position //the continue statement
+}
+
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+func (c *Continue) Inspect() string  { return "continue" }
+
 //String
 type String struct {
 	Value string
@@ -100,6 +273,12 @@ type String struct {
 
 func (s *String) Inspect() string  { return fmt.Sprintf("%s", s.Value) }
 func (s *String) Type() ObjectType { return STRING_OBJ }
+func (s *String) HashKey() HashKey {
+	h := fnv.New64a()
+	h.Write([]byte(s.Value))
+
+	return HashKey{Type: s.Type(), Value: h.Sum64()}
+}
 
 //Integer
 type Integer struct {
@@ -108,6 +287,23 @@ type Integer struct {
 
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
+func (i *Integer) HashKey() HashKey { return HashKey{Type: i.Type(), Value: uint64(i.Value)} }
+
+//Float
+type Float struct {
+	Value float64
+}
+
+// Always shows a decimal point or an exponent, so 3.0 is not shown as 3
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+
+	return s
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
 
 //Boolean
 type Boolean struct {
@@ -118,6 +314,15 @@ func (b *Boolean) Inspect() string { return fmt.Sprintf("%t", b.Value) }
 
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 
+func (b *Boolean) HashKey() HashKey {
+	var value uint64
+	if b.Value {
+		value = 1
+	}
+
+	return HashKey{Type: b.Type(), Value: value}
+}
+
 //Null
 type Null struct{}
 
@@ -125,20 +330,32 @@ func (n *Null) Inspect() string { return "null" }
 
 func (n *Null) Type() ObjectType { return NULL_OBJ }
 
+// Settings of a program, every environment of the program has the same
+type Options struct {
+	StrictIndexing bool // an index out of range is an error instead of null
+}
+
 func NewEnvironment() *Environment {
-	return ExtendEnvironment(nil)
+	return NewEnvironmentWithOptions(Options{})
+}
+
+func NewEnvironmentWithOptions(options Options) *Environment {
+	return &Environment{store: make(map[string]Object), options: options}
 }
 
 func ExtendEnvironment(outer *Environment) *Environment {
 	s := make(map[string]Object)
-	return &Environment{store: s, outer: outer}
+	return &Environment{store: s, outer: outer, options: outer.options}
 }
 
 type Environment struct {
-	store map[string]Object
-	outer *Environment
+	store   map[string]Object
+	outer   *Environment
+	options Options
 }
 
+func (e *Environment) Options() Options { return e.options }
+
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
 
@@ -153,3 +370,15 @@ func (e *Environment) Set(name string, obj Object) Object {
 	e.store[name] = obj
 	return obj
 }
+
+// Updates the nearest existing binding of name, false if there is none
+func (e *Environment) Assign(name string, obj Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = obj
+			return true
+		}
+	}
+
+	return false
+}
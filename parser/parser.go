@@ -11,26 +11,53 @@ import (
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN      // = or += (right associative)
+	TERNARY     // ?: (right associative)
+	LOGICAL_OR  // ||
+	LOGICAL_AND // &&
 	EQUALS      //==
-	LESSGREATER // > or <
+	LESSGREATER // > or < or >= or <=
+	BIT_OR      // |
+	BIT_XOR     // ^
+	BIT_AND     // &
+	SHIFT       // << or >>
 	SUM         // +
-	PRODUCT     // *
-	PREFIX      //-X !X
+	PRODUCT     // * or / or %
+	PREFIX      //-X !X ~X
+	POWER       // ** (right associative, binds tighter than prefix: -2**2 is -(2**2))
 	CALL        // myfoo(X)
-	INDEX       //array[index]
+	INDEX       //array[index] or obj.name
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.QUESTION:        TERNARY,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.PIPE:            BIT_OR,
+	token.CARET:           BIT_XOR,
+	token.AMPERSAND:       BIT_AND,
+	token.SHL:             SHIFT,
+	token.SHR:             SHIFT,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.POWER:           POWER,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
+	token.DOT:             INDEX,
 }
 
 type Parser struct {
@@ -67,27 +94,52 @@ func New(l *lxr.Lexer) *Parser {
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.TILDE, p.parsePrefixExpression)
 	p.registerPrefix(token.TRUE, p.parseBooleanExpression)
 	p.registerPrefix(token.FALSE, p.parseBooleanExpression)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.IF, p.parseIfExpression)
+	p.registerPrefix(token.MATCH, p.parseMatchExpression)
+	p.registerPrefix(token.MACRO, p.parseMacroLiteral)
 	p.registerPrefix(token.FUNCTION, p.parseFunctionLiteral)
 	p.registerPrefix(token.STRING, p.parseStringLiteral)
+	p.registerPrefix(token.TEMPLATE, p.parseStringLiteral)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
+	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
+	p.registerPrefix(token.ILLEGAL, p.parseIllegal)
 
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
 	p.registerInfix(token.PLUS, p.parseInfixExpression)
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
+	p.registerInfix(token.POWER, p.parseInfixExpression)
+	p.registerInfix(token.AMPERSAND, p.parseInfixExpression)
+	p.registerInfix(token.PIPE, p.parseInfixExpression)
+	p.registerInfix(token.CARET, p.parseInfixExpression)
+	p.registerInfix(token.SHL, p.parseInfixExpression)
+	p.registerInfix(token.SHR, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.QUESTION, p.parseConditionalExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 
 	//Read two tokens - sets curToken and peekToken
 	p.nextToken()
@@ -120,6 +172,18 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseLetStatement()
 	case token.RETURN:
 		return p.parseReturnStatement()
+	case token.WHILE:
+		return p.parseWhileStatement()
+	case token.FOR:
+		return p.parseForStatement()
+	case token.BREAK:
+		return p.parseBreakStatement()
+	case token.CONTINUE:
+		return p.parseContinueStatement()
+	case token.IMPORT:
+		return p.parseImportStatement()
+	case token.EXPORT:
+		return p.parseExportStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -140,6 +204,146 @@ func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	return stmt
 }
 
+//While Statement
+func (p *Parser) parseWhileStatement() *ast.WhileStatement {
+	stmt := &ast.WhileStatement{Token: p.curToken}
+
+	if !p.expectedPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Condition = p.parseExpression(LOWEST)
+
+	if !p.expectedPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+//For Statement
+func (p *Parser) parseForStatement() *ast.ForStatement {
+	stmt := &ast.ForStatement{Token: p.curToken}
+
+	if !p.expectedPeek(token.LPAREN) {
+		return nil
+	}
+
+	//one or two loop variables: for(x in ...) or for(k, v in ...)
+	for {
+		if !p.expectedPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Vars = append(stmt.Vars, &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal})
+
+		if len(stmt.Vars) == 2 || !p.peekTokenIs(token.COMMA) {
+			break
+		}
+		p.nextToken()
+	}
+
+	if !p.expectedPeek(token.IN) {
+		return nil
+	}
+
+	p.nextToken()
+
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectedPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+//Break Statement
+func (p *Parser) parseBreakStatement() *ast.BreakStatement {
+	stmt := &ast.BreakStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+//Continue Statement
+func (p *Parser) parseContinueStatement() *ast.ContinueStatement {
+	stmt := &ast.ContinueStatement{Token: p.curToken}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+//Import Statement
+func (p *Parser) parseImportStatement() *ast.ImportStatement {
+	stmt := &ast.ImportStatement{Token: p.curToken}
+
+	if !p.expectedPeek(token.STRING) {
+		return nil
+	}
+
+	stmt.Path = &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
+
+	if p.peekTokenIs(token.AS) {
+		p.nextToken()
+
+		if !p.expectedPeek(token.IDENT) {
+			return nil
+		}
+
+		stmt.Alias = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	}
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	return stmt
+}
+
+//Export Statement
+func (p *Parser) parseExportStatement() *ast.ExportStatement {
+	stmt := &ast.ExportStatement{Token: p.curToken}
+
+	if !p.expectedPeek(token.LET) {
+		return nil
+	}
+
+	stmt.Statement = p.parseLetStatement()
+	if stmt.Statement == nil {
+		return nil
+	}
+
+	return stmt
+}
+
 //Let  Statement
 func (p *Parser) parseLetStatement() *ast.LetStatement {
 	stmt := &ast.LetStatement{Token: p.curToken}
@@ -192,9 +396,14 @@ func (p *Parser) HasErrors() bool {
 	return len(p.errors) != 0
 }
 
+// Report an error at the given position
+func (p *Parser) error(pos token.Position, format string, a ...interface{}) {
+	msg := fmt.Sprintf(format, a...)
+	p.errors = append(p.errors, pos.String()+": "+msg)
+}
+
 func (p *Parser) peekError(t token.TokenType) {
-	msg := fmt.Sprintf("Mismatch token[expected='%s', got='%s']", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.error(p.peekToken.Pos, "Mismatch token[expected='%s', got='%s']", t, p.peekToken.Type)
 }
 
 /*
@@ -229,8 +438,7 @@ func (p *Parser) registerInfix(tokenType token.TokenType, fn infixParseFn) {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
-	msg := fmt.Sprintf("no prefix parse function for token `%s` found", t)
-	p.errors = append(p.errors, msg)
+	p.error(p.curToken.Pos, "no prefix parse function for token `%s` found", t)
 }
 
 func (p *Parser) parseExpression(precedence int) ast.Expression {
@@ -242,6 +450,10 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 	}
 
 	leftExp := prefix()
+	//the error is already reported, don't build on a missing operand
+	if leftExp == nil {
+		return nil
+	}
 
 	for !p.peekTokenIs(token.SEMICOLON) && precedence < p.peekPrecedence() {
 		infix := p.infixParseFns[p.peekToken.Type]
@@ -261,18 +473,81 @@ func (p *Parser) parseIdentifier() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// The lexer reports its errors as ILLEGAL tokens
+func (p *Parser) parseIllegal() ast.Expression {
+	p.error(p.curToken.Pos, "illegal token: %s", p.curToken.Literal)
+	return nil
+}
+
 func (p *Parser) parseStringLiteral() ast.Expression {
+	if p.curTokenIs(token.TEMPLATE) {
+		return p.parseInterpolatedString()
+	}
+
 	return &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal}
 }
 
+// "a${x}b": TEMPLATE("a") x TEMPLATE_END("b")
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	exp := &ast.InterpolatedString{Token: p.curToken}
+	exp.Parts = append(exp.Parts, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+
+	for p.curTokenIs(token.TEMPLATE) {
+		p.nextToken()
+		exp.Parts = append(exp.Parts, p.parseExpression(LOWEST))
+
+		if p.peekTokenIs(token.TEMPLATE) {
+			p.nextToken()
+		} else if !p.expectedPeek(token.TEMPLATE_END) {
+			return nil
+		}
+
+		exp.Parts = append(exp.Parts, &ast.StringLiteral{Token: p.curToken, Value: p.curToken.Literal})
+	}
+
+	return exp
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 
 	al := &ast.ArrayLiteral{Token: p.curToken}
 	al.Elements = p.parseExpressionList(token.RBRACKET)
+	al.Rbracket = p.curToken
 
 	return al
 }
 
+// {<key>: <value>, ...}, a trailing comma is allowed
+func (p *Parser) parseHashLiteral() ast.Expression {
+
+	hash := &ast.HashLiteral{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		key := p.parseExpression(LOWEST)
+
+		if !p.expectedPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parseExpression(LOWEST)
+
+		hash.Pairs = append(hash.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectedPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectedPeek(token.RBRACE) {
+		return nil
+	}
+	hash.Rbrace = p.curToken
+
+	return hash
+}
+
 func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 
 	//parse list: 1,3
@@ -308,8 +583,23 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 	value, err := strconv.ParseInt(p.curToken.Literal, 0, 64)
 
 	if err != nil {
-		msg := fmt.Sprintf("could not parse %q as interger", p.curToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.error(p.curToken.Pos, "could not parse %q as interger", p.curToken.Literal)
+		return nil
+	}
+
+	lit.Value = value
+
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+
+	lit := &ast.FloatLiteral{Token: p.curToken}
+
+	value, err := strconv.ParseFloat(p.curToken.Literal, 64)
+
+	if err != nil {
+		p.error(p.curToken.Pos, "could not parse %q as float", p.curToken.Literal)
 		return nil
 	}
 
@@ -320,15 +610,52 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 
 func (p *Parser) parseFunctionLiteral() ast.Expression {
 	expression := &ast.FunctionLiteral{
-		Token:      p.curToken, //"fn"
-		Parameters: []*ast.Identifier{},
+		Token: p.curToken, //"fn"
 	}
 
-	if !p.expectedPeek(token.LPAREN) {
+	parameters, ok := p.parseFunctionParameters()
+	if !ok {
 		return nil
 	}
+	expression.Parameters = parameters
+
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	return expression
+}
+
+func (p *Parser) parseMacroLiteral() ast.Expression {
+	expression := &ast.MacroLiteral{
+		Token: p.curToken, //"macro"
+	}
+
+	parameters, ok := p.parseFunctionParameters()
+	if !ok {
+		return nil
+	}
+	expression.Parameters = parameters
+
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+
+	expression.Body = p.parseBlockStatement()
+
+	return expression
+}
+
+// (x,y) of fn(x,y){..} and macro(x,y){..}
+func (p *Parser) parseFunctionParameters() ([]*ast.Identifier, bool) {
+	parameters := []*ast.Identifier{}
+
+	if !p.expectedPeek(token.LPAREN) {
+		return nil, false
+	}
 
-	//parse fun parameters: fn(x,y){..}
 	if !p.peekTokenIs(token.RPAREN) {
 		//parse parameters x,y...
 		p.nextToken()
@@ -336,7 +663,7 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
 			iden := p.parseIdentifier()
 
-			expression.Parameters = append(expression.Parameters, iden.(*ast.Identifier))
+			parameters = append(parameters, iden.(*ast.Identifier))
 			if !p.peekTokenIs(token.COMMA) {
 				break //we are done
 			}
@@ -348,16 +675,10 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 
 	//check right paren in fn(...)
 	if !p.expectedPeek(token.RPAREN) {
-		return nil
-	}
-
-	if !p.expectedPeek(token.LBRACE) {
-		return nil
+		return nil, false
 	}
 
-	expression.Body = p.parseBlockStatement()
-
-	return expression
+	return parameters, true
 }
 
 func (p *Parser) parseIfExpression() ast.Expression {
@@ -393,6 +714,24 @@ func (p *Parser) parseIfExpression() ast.Expression {
 
 	//move to else
 	p.nextToken()
+
+	//else if(<cond>){...}: the alternative is a block holding just the nested if
+	if p.peekTokenIs(token.IF) {
+		p.nextToken()
+		elseIf, ok := p.parseIfExpression().(*ast.IfExpression)
+		if !ok {
+			return nil
+		}
+
+		expression.Alternative = &ast.BlockStatement{
+			Token:      elseIf.Token,
+			Statements: []ast.Statement{&ast.ExpressionStatement{Token: elseIf.Token, Expression: elseIf}},
+			Rbrace:     lastBrace(elseIf),
+		}
+
+		return expression
+	}
+
 	//Expecting '{'. validate and move to the alternative left brace: If(<cond>){...}else{...}
 	if !p.expectedPeek(token.LBRACE) {
 		return nil
@@ -403,6 +742,181 @@ func (p *Parser) parseIfExpression() ast.Expression {
 	return expression
 }
 
+// the } closing the last branch of an if/else chain
+func lastBrace(ie *ast.IfExpression) token.Token {
+	if ie.Alternative != nil {
+		return ie.Alternative.Rbrace
+	}
+
+	return ie.Consequence.Rbrace
+}
+
+// match(<value>) { <pattern> [if <guard>] => <expression>, ... }, a trailing comma is allowed
+func (p *Parser) parseMatchExpression() ast.Expression {
+
+	expression := &ast.MatchExpression{Token: p.curToken}
+
+	if !p.expectedPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(LOWEST)
+
+	if !p.expectedPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectedPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		arm := &ast.MatchArm{Pattern: p.parsePattern()}
+		if arm.Pattern == nil {
+			return nil
+		}
+
+		if p.peekTokenIs(token.IF) {
+			p.nextToken()
+			p.nextToken()
+			arm.Guard = p.parseExpression(LOWEST)
+		}
+
+		if !p.expectedPeek(token.ARROW) {
+			return nil
+		}
+
+		p.nextToken()
+		arm.Body = p.parseExpression(LOWEST)
+
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectedPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectedPeek(token.RBRACE) {
+		return nil
+	}
+	expression.Rbrace = p.curToken
+
+	return expression
+}
+
+func (p *Parser) parsePattern() ast.Expression {
+
+	switch p.curToken.Type {
+	case token.IDENT:
+		return &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+	case token.LBRACKET:
+		return p.parseArrayPattern()
+	case token.LBRACE:
+		return p.parseHashPattern()
+	}
+
+	return p.parseLiteralPattern()
+}
+
+// 1, -2.5, "a", true
+func (p *Parser) parseLiteralPattern() ast.Expression {
+
+	exp := p.parseExpression(PREFIX)
+
+	switch e := exp.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean:
+		return exp
+	case *ast.PrefixExpression:
+		switch e.Right.(type) {
+		case *ast.IntegerLiteral, *ast.FloatLiteral:
+			if e.Operator == "-" {
+				return exp
+			}
+		}
+	}
+
+	if exp != nil {
+		p.error(exp.Pos(), "invalid pattern: %s", exp)
+	}
+	return nil
+}
+
+// [<pattern>, ..., ...<rest>], the rest comes last
+func (p *Parser) parseArrayPattern() ast.Expression {
+
+	pattern := &ast.ArrayPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+
+		if p.curTokenIs(token.ELLIPSIS) {
+			if !p.expectedPeek(token.IDENT) {
+				return nil
+			}
+			pattern.Rest = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+			break
+		}
+
+		element := p.parsePattern()
+		if element == nil {
+			return nil
+		}
+		pattern.Elements = append(pattern.Elements, element)
+
+		if !p.peekTokenIs(token.RBRACKET) && !p.expectedPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectedPeek(token.RBRACKET) {
+		return nil
+	}
+	pattern.Rbracket = p.curToken
+
+	return pattern
+}
+
+// {<literal>: <pattern>, ...}
+func (p *Parser) parseHashPattern() ast.Expression {
+
+	pattern := &ast.HashPattern{Token: p.curToken}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+
+		key := p.parseLiteralPattern()
+		if key == nil {
+			return nil
+		}
+
+		if !p.expectedPeek(token.COLON) {
+			return nil
+		}
+
+		p.nextToken()
+		value := p.parsePattern()
+		if value == nil {
+			return nil
+		}
+
+		pattern.Pairs = append(pattern.Pairs, ast.HashPair{Key: key, Value: value})
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectedPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectedPeek(token.RBRACE) {
+		return nil
+	}
+	pattern.Rbrace = p.curToken
+
+	return pattern
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 	block := &ast.BlockStatement{
@@ -420,6 +934,7 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 
 		p.nextToken()
 	}
+	block.Rbrace = p.curToken
 
 	return block
 }
@@ -460,27 +975,117 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	}
 
 	precedence := p.curPrecedence()
+	//right associative: 2**3**2 is 2**(3**2)
+	if p.curTokenIs(token.POWER) {
+		precedence--
+	}
 	p.nextToken()
 	expression.Right = p.parseExpression(precedence)
 
 	return expression
 }
 
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{Token: p.curToken, Target: target}
+
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+	default:
+		if target != nil {
+			p.error(p.curToken.Pos, "invalid assignment target: %s", target)
+		}
+		return nil
+	}
+
+	//right associative: a = b = 5 is a = (b = 5)
+	p.nextToken()
+	expression.Value = p.parseExpression(ASSIGN - 1)
+
+	return expression
+}
+
+func (p *Parser) parseConditionalExpression(condition ast.Expression) ast.Expression {
+	expression := &ast.ConditionalExpression{Token: p.curToken, Condition: condition}
+
+	p.nextToken()
+	expression.Consequence = p.parseExpression(LOWEST)
+
+	if !p.expectedPeek(token.COLON) {
+		return nil
+	}
+
+	//right associative: a ? b : c ? d : e is a ? b : (c ? d : e)
+	p.nextToken()
+	expression.Alternative = p.parseExpression(TERNARY - 1)
+
+	return expression
+}
+
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.curToken, Function: function}
 	exp.Arguments = p.parseCallArguments()
+	exp.Rparen = p.curToken
 
 	return exp
 }
 
+// a[index], or a slice: a[start:stop:step] with every part optional
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
+	tok := p.curToken
+
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		return p.parseSliceExpression(tok, left, index)
+	}
+
+	exp := &ast.IndexExpression{Token: tok, Left: left, Index: index}
+
+	if !p.expectedPeek(token.RBRACKET) {
+		return nil
+	}
+	exp.Rbracket = p.curToken
+
+	return exp
+}
+
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.curToken, Object: object}
+
+	if !p.expectedPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Literal}
+
+	return exp
+}
+
+// the current token is the [ or the start, the peek token the first :
+func (p *Parser) parseSliceExpression(tok token.Token, left, start ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Start: start}
+
 	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	if !p.peekTokenIs(token.COLON) && !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.Stop = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		if !p.peekTokenIs(token.RBRACKET) {
+			p.nextToken()
+			exp.Step = p.parseExpression(LOWEST)
+		}
+	}
 
 	if !p.expectedPeek(token.RBRACKET) {
 		return nil
 	}
+	exp.Rbracket = p.curToken
 
 	return exp
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"monkey/ast"
 	lex "monkey/lexer"
+	"strings"
 	"testing"
 )
 
@@ -18,6 +19,25 @@ func TestNewParser(t *testing.T) {
 	}
 }
 
+func TestParsingFromReader(t *testing.T) {
+	input := "let add = fn(x, y) { x + y };\nadd(1, 2 * 3)"
+
+	l := lex.NewFileReader("add.mk", strings.NewReader(input))
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 2, program)
+
+	expected := "let add = fn(x,y)(x + y);add(1,(2 * 3))"
+	if program.String() != expected {
+		t.Errorf("program.String() wrong. expected=%q, got=%q", expected, program.String())
+	}
+
+	if pos := program.Statements[1].Pos().String(); pos != "add.mk:2:1" {
+		t.Errorf("wrong Pos. expected=%s, got=%s", "add.mk:2:1", pos)
+	}
+}
+
 func TestParsingIndexExpressions(t *testing.T) {
 	input := "myArray[1+1]"
 	l := lex.New(input)
@@ -87,6 +107,66 @@ func TestStringLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestInterpolatedStringExpression(t *testing.T) {
+	input := `"total: ${a + b}, ${c}"`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt := checkExpressionStatement(t, program)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+
+	if len(str.Parts) != 5 {
+		t.Fatalf("wrong number of parts. expected=5, got=%d", len(str.Parts))
+	}
+
+	for i, expected := range []string{"total: ", ", ", ""} {
+		lit, ok := str.Parts[i*2].(*ast.StringLiteral)
+		if !ok {
+			t.Fatalf("parts[%d] not *ast.StringLiteral. got=%T", i*2, str.Parts[i*2])
+		}
+
+		if lit.Value != expected {
+			t.Errorf("parts[%d] wrong. expected=%q, got=%q", i*2, expected, lit.Value)
+		}
+	}
+
+	testInfixExpression(t, str.Parts[1], "a", "+", "b")
+	testIdentifier(t, str.Parts[3], "c")
+
+	if str.String() != "total: ${(a + b)}, ${c}" {
+		t.Errorf("str.String() wrong. got=%q", str.String())
+	}
+
+	if str.End().String() != "1:24" {
+		t.Errorf("wrong End. expected=%s, got=%s", "1:24", str.End())
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []string{
+		`"a ${} b"`,
+		`"a ${x y} b"`,
+		`"a ${x`,
+	}
+
+	for _, input := range tests {
+		l := lex.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		if !p.HasErrors() {
+			t.Errorf("%q: expected parser errors", input)
+		}
+	}
+}
+
 func TestParserReportsErrors(t *testing.T) {
 	input := `
     let x := 5;
@@ -255,6 +335,97 @@ func TestIntegerLiteralExpression(t *testing.T) {
 
 }
 
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff", 255},
+		{"0XfF", 255},
+		{"0o17", 15},
+		{"0b1010", 10},
+		{"1_000_000", 1000000},
+		{"0x_7fff_ffff_ffff_ffff", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		checkStatements(t, 1, program)
+
+		stmt := checkExpressionStatement(t, program)
+
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("Wrong Integer Literal value. [expected=%d, got=%d]", tt.expected, literal.Value)
+		}
+
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() wrong. [expected=%q, got=%q]", tt.input, literal.String())
+		}
+	}
+
+	//no implicit octal
+	for _, input := range []string{"08", "0755"} {
+		l := lex.New(input)
+		p := New(l)
+		p.ParseProgram()
+
+		expected := "1:1: illegal token: malformed number: " + input
+		if len(p.Errors()) != 1 || p.Errors()[0] != expected {
+			t.Errorf("wrong errors. expected=%q, got=%q", expected, p.Errors())
+		}
+	}
+}
+
+func TestIntegerLiteralOverflow(t *testing.T) {
+	l := lex.New("0x8000_0000_0000_0000")
+	p := New(l)
+	p.ParseProgram()
+
+	expected := `1:1: could not parse "0x8000_0000_0000_0000" as interger`
+	if len(p.Errors()) != 1 || p.Errors()[0] != expected {
+		t.Fatalf("wrong errors. expected=%q, got=%q", expected, p.Errors())
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"1.5;", 1.5},
+		{"2e10", 2e10},
+		{"0.125", 0.125},
+		{"1_000.5", 1000.5},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		checkStatements(t, 1, program)
+
+		stmt := checkExpressionStatement(t, program)
+
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+
+		if literal.Value != tt.expected {
+			t.Errorf("Wrong Float Literal value. [expected=%g, got=%g]", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestBooleanExpression(t *testing.T) {
 	tests := []struct {
 		input           string
@@ -291,6 +462,7 @@ func TestParsingPrefixExpressions(t *testing.T) {
 		value    interface{}
 	}{
 		{"!5;", "!", 5},
+		{"~5;", "~", 5},
 		{"-15;", "-", 15},
 		{"!true;", "!", true},
 		{"!false;", "!", false},
@@ -393,6 +565,17 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"5 ** 5;", 5, "**", 5},
+		{"5 & 5;", 5, "&", 5},
+		{"5 | 5;", 5, "|", 5},
+		{"5 ^ 5;", 5, "^", 5},
+		{"5 << 5;", 5, "<<", 5},
+		{"5 >> 5;", 5, ">>", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"true && false;", true, "&&", false},
+		{"true || false;", true, "||", false},
 		{"true == true;", true, "==", true},
 		{"true != false;", true, "!=", false},
 		{"false == false;", false, "==", false},
@@ -462,6 +645,86 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"5 < 4 != 3 > 4",
 			"((5 < 4) != (3 > 4))",
 		},
+		{
+			"a * b % c",
+			"((a * b) % c)",
+		},
+		{
+			"2 ** 3 ** 2",
+			"(2 ** (3 ** 2))",
+		},
+		{
+			"-2 ** 2",
+			"(-(2 ** 2))",
+		},
+		{
+			"2 ** -1 * 3",
+			"((2 ** (-1)) * 3)",
+		},
+		{
+			"a | b ^ c & d",
+			"(a | (b ^ (c & d)))",
+		},
+		{
+			"a & b << 1 + c",
+			"(a & (b << (1 + c)))",
+		},
+		{
+			"a & b == c",
+			"((a & b) == c)",
+		},
+		{
+			"a | b < c >> d",
+			"((a | b) < (c >> d))",
+		},
+		{
+			"~a & ~b",
+			"((~a) & (~b))",
+		},
+		{
+			"a = b = c + 1",
+			"a = b = (c + 1)",
+		},
+		{
+			"a += b || c",
+			"a += (b || c)",
+		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a || b ? c + 1 : d * 2",
+			"((a || b) ? (c + 1) : (d * 2))",
+		},
+		{
+			"a ? b : c ? d : e",
+			"(a ? b : (c ? d : e))",
+		},
+		{
+			"a ? b ? c : d : e",
+			"(a ? (b ? c : d) : e)",
+		},
+		{
+			"x = a ? b : c",
+			"x = (a ? b : c)",
+		},
+		{
+			"[a ? 1 : 2, f(b ? c : d)]",
+			"[(a ? 1 : 2), f((b ? c : d))]",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a == b && c != d || !e",
+			"(((a == b) && (c != d)) || (!e))",
+		},
+		{
+			"5 >= 4 == 3 <= 4 + 1",
+			"((5 >= 4) == (3 <= (4 + 1)))",
+		},
 		{
 			"3 + 4 * 5 == 3 * 1 + 4 * 5",
 			"((3 + (4 * 5)) == ((3 * 1) + (4 * 5)))",
@@ -514,6 +777,14 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a*b[2], b[1], 2*[1,2][1])",
 			"add((a * (b[2])),(b[1]),(2 * ([1, 2][1])))",
 		},
+		{
+			"a /* comment */ + b // comment",
+			"(a + b)",
+		},
+		{
+			"// first\na * b; /* second */ c",
+			"(a * b)c",
+		},
 	}
 
 	for _, tt := range tests {
@@ -612,127 +883,719 @@ func testInfixExpression(t *testing.T, exp ast.Expression, left interface{},
 	return true
 }
 
-func TestIfExpression(t *testing.T) {
-	input := `if(x<y){x}`
-
-	l := lex.New(input)
-	p := New(l)
-
-	program := p.ParseProgram()
-	checkParserErrors(t, p)
-	checkStatements(t, 1, program)
-	stmt := checkExpressionStatement(t, program)
-
-	exp, ok := stmt.Expression.(*ast.IfExpression)
-	if !ok {
-		t.Fatalf("exp is wrong type. [expected=*ast.IfExpression, got=%T]", stmt.Expression)
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		input         string
+		expectedName  string
+		operator      string
+		expectedValue interface{}
+	}{
+		{"x = 5;", "x", "=", 5},
+		{"x += 5;", "x", "+=", 5},
+		{"total -= y", "total", "-=", "y"},
+		{"x *= true", "x", "*=", true},
+		{"x /= 2", "x", "/=", 2},
 	}
 
-	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
-		return
-	}
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		checkStatements(t, 1, program)
 
-	//check the consequence of the 'if'
-	if ll := len(exp.Consequence.Statements); ll != 1 {
-		t.Errorf("consequence is not 1 statement. got=%d\n", ll)
-	}
+		stmt := checkExpressionStatement(t, program)
 
-	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Errorf("Statement[0] is not ast.ExpressionStatement. got=%T\n", consequence)
-	}
+		exp, ok := stmt.Expression.(*ast.AssignExpression)
+		if !ok {
+			t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
+		}
 
-	if !testIdentifier(t, consequence.Expression, "x") {
-		return
-	}
+		if !testIdentifier(t, exp.Target, tt.expectedName) {
+			return
+		}
 
-	if exp.Alternative != nil {
-		t.Errorf("exp.Alternative was not nil. got=%+v,", exp.Alternative)
-	}
+		if exp.TokenLiteral() != tt.operator {
+			t.Fatalf("wrong operator. expected=%q, got=%q", tt.operator, exp.TokenLiteral())
+		}
 
+		if !testLiteralExpression(t, exp.Value, tt.expectedValue) {
+			return
+		}
+	}
 }
 
-func TestIfElseExpression(t *testing.T) {
-	input := `if (x < y) { x } else { y }`
+func TestIndexAssignExpression(t *testing.T) {
+	input := "arr[i + 1] += 5"
 
 	l := lex.New(input)
 	p := New(l)
 	program := p.ParseProgram()
 	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
 
-	if len(program.Statements) != 1 {
-		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
-			1, len(program.Statements))
-	}
+	stmt := checkExpressionStatement(t, program)
 
-	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	exp, ok := stmt.Expression.(*ast.AssignExpression)
 	if !ok {
-		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
-			program.Statements[0])
+		t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
 	}
 
-	exp, ok := stmt.Expression.(*ast.IfExpression)
+	target, ok := exp.Target.(*ast.IndexExpression)
 	if !ok {
-		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
-	}
-
-	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
-		return
+		t.Fatalf("exp.Target not *ast.IndexExpression. got=%T", exp.Target)
 	}
 
-	if len(exp.Consequence.Statements) != 1 {
-		t.Errorf("consequence is not 1 statements. got=%d\n",
-			len(exp.Consequence.Statements))
-	}
+	testIdentifier(t, target.Left, "arr")
+	testInfixExpression(t, target.Index, "i", "+", 1)
+	testIntegerLiteral(t, exp.Value, 5)
 
-	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T",
-			exp.Consequence.Statements[0])
+	if exp.String() != "(arr[(i + 1)]) += 5" {
+		t.Errorf("exp.String() wrong. got=%q", exp.String())
 	}
+}
 
-	if !testIdentifier(t, consequence.Expression, "x") {
-		return
+func TestSliceExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a[1:2]", "(a[1:2])"},
+		{"a[1:]", "(a[1:])"},
+		{"a[:2]", "(a[:2])"},
+		{"a[:]", "(a[:])"},
+		{"a[::2]", "(a[::2])"},
+		{"a[::]", "(a[:])"},
+		{"a[-1:x + 1:-1]", "(a[(-1):(x + 1):(-1)])"},
+		{"a[1:2][0]", "((a[1:2])[0])"},
+		{"a[b[1:]:]", "(a[(b[1:]):])"},
 	}
 
-	if len(exp.Alternative.Statements) != 1 {
-		t.Errorf("exp.Alternative.Statements does not contain 1 statements. got=%d\n",
-			len(exp.Alternative.Statements))
-	}
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
 
-	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
-	if !ok {
-		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T",
-			exp.Alternative.Statements[0])
-	}
+		stmt := checkExpressionStatement(t, program)
 
-	if !testIdentifier(t, alternative.Expression, "y") {
-		return
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("wrong String(). expected=%q, got=%q", tt.expected, stmt.Expression.String())
+		}
 	}
-}
-
-func TestFunctionLiteralParsing(t *testing.T) {
-	input := `fn(x,y){x + y;}`
 
-	l := lex.New(input)
+	l := lex.New("a[1:2:3]")
 	p := New(l)
 	program := p.ParseProgram()
-
 	checkParserErrors(t, p)
-	checkStatements(t, 1, program)
 
 	stmt := checkExpressionStatement(t, program)
-
-	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	slice, ok := stmt.Expression.(*ast.SliceExpression)
 	if !ok {
-
-		t.Fatalf("Statements[0] is not ast.FunctionLiteral. got=%T",
-			stmt.Expression)
+		t.Fatalf("exp not *ast.SliceExpression. got=%T", stmt.Expression)
 	}
 
-	if l := len(function.Parameters); l != 2 {
-		t.Fatalf("Function literal parameters wrong. exp=2, got=%d", l)
-	}
+	testIdentifier(t, slice.Left, "a")
+	testIntegerLiteral(t, slice.Start, 1)
+	testIntegerLiteral(t, slice.Stop, 2)
+	testIntegerLiteral(t, slice.Step, 3)
+}
+
+func TestMemberExpressionParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a.b", "(a.b)"},
+		{"a.b.c", "((a.b).c)"},
+		{"a.b(1, 2)", "(a.b)(1,2)"},
+		{"a.b().c()", "((a.b)().c)()"},
+		{"-a.b", "(-(a.b))"},
+		{"a.b * c.d", "((a.b) * (c.d))"},
+		{"a[0].b", "((a[0]).b)"},
+		{"a.b[0]", "((a.b)[0])"},
+		{`"abc".upper()`, "(abc.upper)()"},
+		{"1.len()", "(1.len)()"},
+		{"a.b = a.b + 1", "(a.b) = ((a.b) + 1)"},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := checkExpressionStatement(t, program)
+
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("wrong String(). expected=%q, got=%q", tt.expected, stmt.Expression.String())
+		}
+	}
+
+	l := lex.New("obj.field")
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := checkExpressionStatement(t, program)
+	member, ok := stmt.Expression.(*ast.MemberExpression)
+	if !ok {
+		t.Fatalf("exp not *ast.MemberExpression. got=%T", stmt.Expression)
+	}
+
+	testIdentifier(t, member.Object, "obj")
+	testIdentifier(t, member.Property, "field")
+
+	l = lex.New("a.1")
+	p = New(l)
+	p.ParseProgram()
+
+	expected := "1:3: Mismatch token[expected='IDENT', got='INT']"
+	if len(p.Errors()) == 0 || p.Errors()[0] != expected {
+		t.Errorf("wrong errors. expected=%q, got=%q", expected, p.Errors())
+	}
+}
+
+func TestHashLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected map[string]int64
+		str      string
+	}{
+		{`{}`, map[string]int64{}, "{}"},
+		{`{"one": 1, "two": 2, "three": 3}`, map[string]int64{"one": 1, "two": 2, "three": 3}, `{one: 1, two: 2, three: 3}`},
+		{`{"one": 1,}`, map[string]int64{"one": 1}, `{one: 1}`},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := checkExpressionStatement(t, program)
+
+		hash, ok := stmt.Expression.(*ast.HashLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.HashLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(hash.Pairs) != len(tt.expected) {
+			t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+		}
+
+		for _, pair := range hash.Pairs {
+			key, ok := pair.Key.(*ast.StringLiteral)
+			if !ok {
+				t.Fatalf("key is not ast.StringLiteral. got=%T", pair.Key)
+			}
+
+			testIntegerLiteral(t, pair.Value, tt.expected[key.Value])
+		}
+
+		if hash.String() != tt.str {
+			t.Errorf("hash.String() wrong. expected=%q, got=%q", tt.str, hash.String())
+		}
+	}
+}
+
+func TestHashLiteralWithExpressions(t *testing.T) {
+	input := `{1 + 1: 0 + 1, true: 10 - 8, x: 15 / 5}`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := checkExpressionStatement(t, program)
+
+	hash, ok := stmt.Expression.(*ast.HashLiteral)
+	if !ok {
+		t.Fatalf("exp not *ast.HashLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(hash.Pairs) != 3 {
+		t.Fatalf("hash.Pairs has wrong length. got=%d", len(hash.Pairs))
+	}
+
+	testInfixExpression(t, hash.Pairs[0].Key, 1, "+", 1)
+	testInfixExpression(t, hash.Pairs[0].Value, 0, "+", 1)
+	testLiteralExpression(t, hash.Pairs[1].Key, true)
+	testInfixExpression(t, hash.Pairs[1].Value, 10, "-", 8)
+	testIdentifier(t, hash.Pairs[2].Key, "x")
+	testInfixExpression(t, hash.Pairs[2].Value, 15, "/", 5)
+}
+
+func TestInvalidAssignmentTarget(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 = 1", "1:3: invalid assignment target: 5"},
+		{"a + b = 1", "1:7: invalid assignment target: (a + b)"},
+		{"f() += 1", "1:5: invalid assignment target: f()"},
+		{"a[0:1] = [3]", "1:8: invalid assignment target: (a[0:1])"},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors. expected=%q, got=%q", tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	input := `if(x<y){x}`
+
+	l := lex.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+	stmt := checkExpressionStatement(t, program)
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("exp is wrong type. [expected=*ast.IfExpression, got=%T]", stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	//check the consequence of the 'if'
+	if ll := len(exp.Consequence.Statements); ll != 1 {
+		t.Errorf("consequence is not 1 statement. got=%d\n", ll)
+	}
+
+	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Errorf("Statement[0] is not ast.ExpressionStatement. got=%T\n", consequence)
+	}
+
+	if !testIdentifier(t, consequence.Expression, "x") {
+		return
+	}
+
+	if exp.Alternative != nil {
+		t.Errorf("exp.Alternative was not nil. got=%+v,", exp.Alternative)
+	}
+
+}
+
+func TestElseIfExpression(t *testing.T) {
+	input := `if (x < y) { x } else if (x > y) { y } else { z }`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt := checkExpressionStatement(t, program)
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if len(exp.Alternative.Statements) != 1 {
+		t.Fatalf("exp.Alternative.Statements does not contain 1 statements. got=%d\n",
+			len(exp.Alternative.Statements))
+	}
+
+	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T",
+			exp.Alternative.Statements[0])
+	}
+
+	elseIf, ok := alternative.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("alternative is not ast.IfExpression. got=%T", alternative.Expression)
+	}
+
+	if !testInfixExpression(t, elseIf.Condition, "x", ">", "y") {
+		return
+	}
+
+	if elseIf.Alternative == nil || len(elseIf.Alternative.Statements) != 1 {
+		t.Fatalf("else if has no final else. got=%+v", elseIf.Alternative)
+	}
+
+	if exp.String() != "if(x < y) x else if(x > y) y else z" {
+		t.Errorf("exp.String() wrong. got=%q", exp.String())
+	}
+
+	if end := exp.End(); end.Offset != len(input) {
+		t.Errorf("exp.End() wrong. expected offset %d, got=%d", len(input), end.Offset)
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `match (x) {
+		0 => "zero",
+		-1 => "minus one",
+		[first, _, ...rest] if first > 1 => rest,
+		{"name": n, "tags": []} => n,
+		_ => x,
+	}`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt := checkExpressionStatement(t, program)
+
+	exp, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+
+	testIdentifier(t, exp.Value, "x")
+
+	if len(exp.Arms) != 5 {
+		t.Fatalf("wrong number of arms. expected=5, got=%d", len(exp.Arms))
+	}
+
+	testIntegerLiteral(t, exp.Arms[0].Pattern, 0)
+
+	array, ok := exp.Arms[2].Pattern.(*ast.ArrayPattern)
+	if !ok {
+		t.Fatalf("Arms[2].Pattern is not ast.ArrayPattern. got=%T", exp.Arms[2].Pattern)
+	}
+	if len(array.Elements) != 2 || array.Rest == nil {
+		t.Fatalf("wrong array pattern. got=%s", array)
+	}
+	testInfixExpression(t, exp.Arms[2].Guard, "first", ">", 1)
+
+	if _, ok := exp.Arms[3].Pattern.(*ast.HashPattern); !ok {
+		t.Fatalf("Arms[3].Pattern is not ast.HashPattern. got=%T", exp.Arms[3].Pattern)
+	}
+
+	expected := `match(x) {0 => zero, (-1) => minus one, [first, _, ...rest] if (first > 1) => rest, {name: n, tags: []} => n, _ => x}`
+	if exp.String() != expected {
+		t.Errorf("exp.String() wrong. expected=%q, got=%q", expected, exp.String())
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"match (x) { a + 1 => 1 }", "1:15: Mismatch token[expected='=>', got='+']"},
+		{"match (x) { f(1) => 1 }", "1:14: Mismatch token[expected='=>', got='(']"},
+		{"match (x) { !true => 1 }", "1:13: invalid pattern: (!true)"},
+		{`match (x) { "${x}" => 1 }`, "1:13: invalid pattern: ${x}"},
+		{"match (x) { [...a, b] => 1 }", "1:18: Mismatch token[expected=']', got=',']"},
+		{"match (x) { {a: 1} => 1 }", "1:14: invalid pattern: a"},
+		{"match (x) { 1 => 1 2 => 2 }", "1:20: Mismatch token[expected=',', got='INT']"},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors. expected=%q, got=%q", tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestImportStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		path     string
+		alias    string
+		expected string
+	}{
+		{`import "path/to/lib.mk"`, "path/to/lib.mk", "", `import "path/to/lib.mk";`},
+		{`import "lib" as name;`, "lib", "name", `import "lib" as name;`},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		checkStatements(t, 1, program)
+
+		stmt, ok := program.Statements[0].(*ast.ImportStatement)
+		if !ok {
+			t.Fatalf("stmt is wrong type. [expected=*ast.ImportStatement, got=%T]", program.Statements[0])
+		}
+
+		if stmt.Path.Value != tt.path {
+			t.Errorf("stmt.Path wrong. expected=%q, got=%q", tt.path, stmt.Path.Value)
+		}
+
+		if tt.alias == "" && stmt.Alias != nil {
+			t.Errorf("stmt.Alias is not nil. got=%s", stmt.Alias)
+		}
+		if tt.alias != "" {
+			testIdentifier(t, stmt.Alias, tt.alias)
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestExportStatement(t *testing.T) {
+	input := `export let x = 5;`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt, ok := program.Statements[0].(*ast.ExportStatement)
+	if !ok {
+		t.Fatalf("stmt is wrong type. [expected=*ast.ExportStatement, got=%T]", program.Statements[0])
+	}
+
+	if !testLetStatement(t, stmt.Statement, "x") {
+		return
+	}
+
+	if stmt.String() != "export let x = 5;" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestImportExportErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import lib", "1:8: Mismatch token[expected='STRING', got='IDENT']"},
+		{`import "a${b}"`, "1:8: Mismatch token[expected='STRING', got='TEMPLATE']"},
+		{`import "lib" as "name"`, "1:17: Mismatch token[expected='IDENT', got='STRING']"},
+		{"export x = 1", "1:8: Mismatch token[expected='LET', got='IDENT']"},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors. expected=%q, got=%q", tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestWhileStatement(t *testing.T) {
+	input := `while(x<y){x += 1}`
+
+	l := lex.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("stmt is wrong type. [expected=*ast.WhileStatement, got=%T]", program.Statements[0])
+	}
+
+	if !testInfixExpression(t, stmt.Condition, "x", "<", "y") {
+		return
+	}
+
+	if ll := len(stmt.Body.Statements); ll != 1 {
+		t.Fatalf("body is not 1 statement. got=%d\n", ll)
+	}
+
+	if stmt.String() != "while(x < y) x += 1" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		vars     []string
+		expected string
+	}{
+		{"for(x in xs){ x }", []string{"x"}, "for(x in xs) x"},
+		{"for(k, v in h){ v };", []string{"k", "v"}, "for(k, v in h) v"},
+		{"for(i in range(3)){ i }", []string{"i"}, "for(i in range(3)) i"},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		checkStatements(t, 1, program)
+
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("stmt is wrong type. [expected=*ast.ForStatement, got=%T]", program.Statements[0])
+		}
+
+		if len(stmt.Vars) != len(tt.vars) {
+			t.Fatalf("wrong number of loop variables. expected=%d, got=%d", len(tt.vars), len(stmt.Vars))
+		}
+
+		for i, name := range tt.vars {
+			testIdentifier(t, stmt.Vars[i], name)
+		}
+
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestForStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"for(x xs){ x }", "1:7: Mismatch token[expected='IN', got='IDENT']"},
+		{"for(a, b, c in xs){ a }", "1:9: Mismatch token[expected='IN', got=',']"},
+		{"for(1 in xs){ 1 }", "1:5: Mismatch token[expected='IDENT', got='INT']"},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		if len(p.Errors()) == 0 || p.Errors()[0] != tt.expected {
+			t.Errorf("wrong errors. expected=%q, got=%q", tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestBreakContinueStatements(t *testing.T) {
+	input := `while(true){ break; continue }`
+
+	l := lex.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt, ok := program.Statements[0].(*ast.WhileStatement)
+	if !ok {
+		t.Fatalf("stmt is wrong type. [expected=*ast.WhileStatement, got=%T]", program.Statements[0])
+	}
+
+	if ll := len(stmt.Body.Statements); ll != 2 {
+		t.Fatalf("body is not 2 statements. got=%d\n", ll)
+	}
+
+	if _, ok := stmt.Body.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("Statements[0] is not *ast.BreakStatement. got=%T", stmt.Body.Statements[0])
+	}
+
+	if _, ok := stmt.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("Statements[1] is not *ast.ContinueStatement. got=%T", stmt.Body.Statements[1])
+	}
+
+	if stmt.String() != "whiletrue break;continue;" {
+		t.Errorf("stmt.String() wrong. got=%q", stmt.String())
+	}
+}
+
+func TestIfElseExpression(t *testing.T) {
+	input := `if (x < y) { x } else { y }`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain %d statements. got=%d\n",
+			1, len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T",
+			program.Statements[0])
+	}
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if !testInfixExpression(t, exp.Condition, "x", "<", "y") {
+		return
+	}
+
+	if len(exp.Consequence.Statements) != 1 {
+		t.Errorf("consequence is not 1 statements. got=%d\n",
+			len(exp.Consequence.Statements))
+	}
+
+	consequence, ok := exp.Consequence.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T",
+			exp.Consequence.Statements[0])
+	}
+
+	if !testIdentifier(t, consequence.Expression, "x") {
+		return
+	}
+
+	if len(exp.Alternative.Statements) != 1 {
+		t.Errorf("exp.Alternative.Statements does not contain 1 statements. got=%d\n",
+			len(exp.Alternative.Statements))
+	}
+
+	alternative, ok := exp.Alternative.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("Statements[0] is not ast.ExpressionStatement. got=%T",
+			exp.Alternative.Statements[0])
+	}
+
+	if !testIdentifier(t, alternative.Expression, "y") {
+		return
+	}
+}
+
+func TestFunctionLiteralParsing(t *testing.T) {
+	input := `fn(x,y){x + y;}`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt := checkExpressionStatement(t, program)
+
+	function, ok := stmt.Expression.(*ast.FunctionLiteral)
+	if !ok {
+
+		t.Fatalf("Statements[0] is not ast.FunctionLiteral. got=%T",
+			stmt.Expression)
+	}
+
+	if l := len(function.Parameters); l != 2 {
+		t.Fatalf("Function literal parameters wrong. exp=2, got=%d", l)
+	}
 
 	testLiteralExpression(t, function.Parameters[0], "x")
 	testLiteralExpression(t, function.Parameters[1], "y")
@@ -749,6 +1612,41 @@ func TestFunctionLiteralParsing(t *testing.T) {
 	testInfixExpression(t, bodystmt.Expression, "x", "+", "y")
 }
 
+func TestMacroLiteralParsing(t *testing.T) {
+	input := `macro(x, y) { x + y; }`
+
+	l := lex.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	checkStatements(t, 1, program)
+
+	stmt := checkExpressionStatement(t, program)
+
+	macro, ok := stmt.Expression.(*ast.MacroLiteral)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MacroLiteral. got=%T", stmt.Expression)
+	}
+
+	if len(macro.Parameters) != 2 {
+		t.Fatalf("macro literal parameters wrong. want 2, got=%d\n", len(macro.Parameters))
+	}
+
+	testLiteralExpression(t, macro.Parameters[0], "x")
+	testLiteralExpression(t, macro.Parameters[1], "y")
+
+	if len(macro.Body.Statements) != 1 {
+		t.Fatalf("macro.Body.Statements has not 1 statements. got=%d\n", len(macro.Body.Statements))
+	}
+
+	bodyStmt, ok := macro.Body.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("macro body stmt is not ast.ExpressionStatement. got=%T", macro.Body.Statements[0])
+	}
+
+	testInfixExpression(t, bodyStmt.Expression, "x", "+", "y")
+}
+
 func TestFunctionParameterParsing(t *testing.T) {
 	tests := []struct {
 		input          string
@@ -819,3 +1717,69 @@ func TestCallExpresssionParsing(t *testing.T) {
 	testInfixExpression(t, exp.Arguments[2], 4, "+", 5)
 
 }
+
+func TestNodePositions(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedPos string
+		expectedEnd string
+	}{
+		{"foobar", "1:1", "1:7"},
+		{"  5 + 10", "1:3", "1:9"},
+		{"-a * b", "1:1", "1:7"},
+		{"let x = 5;", "1:1", "1:10"},
+		{"return x;", "1:1", "1:9"},
+		{"add(1, 2)", "1:1", "1:10"},
+		{"[1, 2][0]", "1:1", "1:10"},
+		{"fn(x) {\n  x\n}", "1:1", "3:2"},
+		{"if (x) { 1 } else { 2 }", "1:1", "1:24"},
+		{"\"ab\"", "1:1", "1:5"},
+	}
+
+	for _, tt := range tests {
+		l := lex.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		checkStatements(t, 1, program)
+
+		stmt := program.Statements[0]
+		if stmt.Pos().String() != tt.expectedPos {
+			t.Errorf("%q: wrong Pos. expected=%s, got=%s", tt.input, tt.expectedPos, stmt.Pos())
+		}
+
+		if stmt.End().String() != tt.expectedEnd {
+			t.Errorf("%q: wrong End. expected=%s, got=%s", tt.input, tt.expectedEnd, stmt.End())
+		}
+	}
+}
+
+func TestParserErrorPositions(t *testing.T) {
+	input := "let x = 5;\nlet = 10;"
+
+	l := lex.NewFile("test.mk", input)
+	p := New(l)
+	p.ParseProgram()
+
+	if len(p.Errors()) == 0 {
+		t.Fatalf("expected parser errors")
+	}
+
+	expected := "test.mk:2:5: Mismatch token[expected='IDENT', got='=']"
+	if p.Errors()[0] != expected {
+		t.Errorf("wrong error. expected=%q, got=%q", expected, p.Errors()[0])
+	}
+}
+
+func TestIllegalTokenErrors(t *testing.T) {
+	input := `let s = "bad \q";`
+
+	l := lex.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	expected := `1:9: illegal token: unknown escape sequence: \q`
+	if len(p.Errors()) != 1 || p.Errors()[0] != expected {
+		t.Fatalf("wrong errors. expected=%q, got=%q", expected, p.Errors())
+	}
+}
@@ -12,9 +12,10 @@ import (
 
 const PROMPRT = " >> "
 
-func Start(in io.Reader, out io.Writer) {
+func Start(in io.Reader, out io.Writer, options object.Options) {
 	scanner := bufio.NewScanner(in)
-	env := object.NewEnvironment()
+	env := object.NewEnvironmentWithOptions(options)
+	macroEnv := object.NewEnvironmentWithOptions(options)
 
 	for {
 		fmt.Printf(PROMPRT)
@@ -34,7 +35,14 @@ func Start(in io.Reader, out io.Writer) {
 			continue
 		}
 
-		obj := evaluator.Eval(program, env)
+		evaluator.DefineMacros(program, macroEnv)
+		expanded, err := evaluator.ExpandMacros(program, macroEnv)
+		if err != nil {
+			printParserErrors(out, []string{err.Error()})
+			continue
+		}
+
+		obj := evaluator.Eval(expanded, env)
 
 		if obj == nil {
 			//	io.WriteString(out, "\tCant evaluate the expression!\n")
@@ -1,10 +1,51 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position  // position of the first char of the token
+	End     Position  // position immediately after the token
+	Leading []Comment // comments preceding the token
+}
+
+// Comment, kept as trivia of the token that follows it
+// e.g. a line comment or a block comment
+type Comment struct {
+	Text string // the comment text, including the // or /* */ markers
+	Pos  Position
+	End  Position
+}
+
+/*
+* Position in the source
+* Line and Column start at 1, Offset is the byte offset starting at 0
+ */
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// A Position is valid if the line number is > 0
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+// file:line:column, or line:column when there is no file name
+func (p Position) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+
+	s := fmt.Sprintf("%d:%d", p.Line, p.Column)
+	if p.Filename != "" {
+		s = p.Filename + ":" + s
+	}
+
+	return s
 }
 
 const (
@@ -14,9 +55,15 @@ const (
 	// Identifiers + literals
 	IDENT = "IDENT" //add, foobar, x, y...
 	INT   = "INT"
+	FLOAT = "FLOAT"
 
 	// Operators
-	ASSIGN   = "="
+	ASSIGN          = "="
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+
 	PLUS     = "+"
 	MINUS    = "-"
 	ASTERISK = "*"
@@ -24,13 +71,32 @@ const (
 	BANG     = "!"
 	LT       = "<"
 	GT       = ">"
+	PERCENT  = "%"
+	POWER    = "**"
+
+	// Bitwise operators
+	AMPERSAND = "&"
+	PIPE      = "|"
+	CARET     = "^"
+	TILDE     = "~"
+	SHL       = "<<"
+	SHR       = ">>"
 
 	EQ     = "=="
 	NOT_EQ = "!="
+	LT_EQ  = "<="
+	GT_EQ  = ">="
+	AND    = "&&"
+	OR     = "||"
 
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
+	COLON     = ":"
+	DOT       = "."
+	QUESTION  = "?"
+	ARROW     = "=>"
+	ELLIPSIS  = "..."
 
 	LPAREN = "("
 	RPAREN = ")"
@@ -45,21 +111,46 @@ const (
 	IF       = "IF"
 	ELSE     = "ELSE"
 	RETURN   = "RETURN"
+	WHILE    = "WHILE"
+	FOR      = "FOR"
+	IN       = "IN"
+	BREAK    = "BREAK"
+	CONTINUE = "CONTINUE"
+	MATCH    = "MATCH"
+	MACRO    = "MACRO"
+	IMPORT   = "IMPORT"
+	EXPORT   = "EXPORT"
+	AS       = "AS"
 
 	//types
 	STRING   = "STRING"
 	LBRACKET = "["
 	RBRACKET = "]"
+
+	//parts of a string with embedded expressions, "a${x}b${y}c":
+	//TEMPLATE("a") x TEMPLATE("b") y TEMPLATE_END("c")
+	TEMPLATE     = "TEMPLATE"
+	TEMPLATE_END = "TEMPLATE_END"
 )
 
 var keywords = map[string]TokenType{
-	"fn":     FUNCTION,
-	"let":    LET,
-	"true":   TRUE,
-	"false":  FALSE,
-	"if":     IF,
-	"else":   ELSE,
-	"return": RETURN,
+	"fn":       FUNCTION,
+	"let":      LET,
+	"true":     TRUE,
+	"false":    FALSE,
+	"if":       IF,
+	"else":     ELSE,
+	"return":   RETURN,
+	"while":    WHILE,
+	"for":      FOR,
+	"in":       IN,
+	"break":    BREAK,
+	"continue": CONTINUE,
+	"match":    MATCH,
+	"macro":    MACRO,
+	"import":   IMPORT,
+	"export":   EXPORT,
+	"as":       AS,
 }
 
 func LookupIndent(ident string) TokenType {